@@ -2,16 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"src/internal/api"
 	"src/internal/cache"
 	"src/internal/config"
 	"src/internal/middleware"
 	"src/internal/queue"
+	"src/internal/services/admin"
+	"src/internal/services/status"
 	"src/internal/services/upload"
 	"src/internal/services/video_processing"
 	"src/internal/storage"
+	"sync"
 	"syscall"
 
 	"github.com/gin-gonic/gin"
@@ -42,36 +48,39 @@ func main() {
 	}
 	defer rabbitMQClient.Close()
 
-	publisher := queue.NewPublisher(rabbitMQClient.GetChannel())
+	publisher := queue.NewPublisher(rabbitMQClient)
+	deadLetters := queue.NewDeadLetterQueue(rabbitMQClient, publisher)
+	outbox := queue.NewOutbox(redisClient, publisher)
 
-	processor := video_processing.NewProcessorWithMinIO(minioClient)
+	processor := video_processing.NewProcessorWithMinIO(minioClient, cfg.WorkDir)
+	if removed, err := processor.CleanupOrphanedWorkDirs(); err != nil {
+		log.Printf("⚠️ Erro ao limpar diretórios de trabalho órfãos: %v", err)
+	} else if removed > 0 {
+		log.Printf("🧹 %d diretórios de trabalho órfãos removidos de %s", removed, cfg.WorkDir)
+	}
 
-	consumer := queue.NewConsumer(rabbitMQClient.GetChannel(), processor, minioClient)
+	videoAPI := api.NewHTTPClientFromEnv()
+	consumer := queue.NewConsumer(rabbitMQClient, processor, minioClient, redisClient, videoAPI, cfg.WorkerPoolSize)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	go outbox.Run(ctx)
+	go upload.RunStaleUploadCleanup(ctx, minioClient)
+
 	go func() {
-		if err := consumer.StartProcessing(ctx); err != nil {
+		if err := consumer.StartProcessing(ctx); err != nil && !errors.Is(err, context.Canceled) {
 			log.Printf("Erro no consumer de processamento: %v", err)
 		}
 	}()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		<-sigChan
-		log.Println("🛑 Recebido sinal de shutdown, encerrando...")
-		cancel()
-	}()
-
 	router := gin.Default()
 
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Upload-Offset")
+		c.Header("Access-Control-Expose-Headers", "Location, Upload-Offset, Upload-Length")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -82,13 +91,104 @@ func main() {
 	})
 
 	router.POST("/upload/video", middleware.AuthMiddleware(), func(c *gin.Context) {
-		upload.HandleVideoUpload(c, minioClient, publisher)
+		upload.HandleVideoUpload(c, minioClient, redisClient, videoAPI, outbox)
+	})
+
+	router.POST("/upload/video/resumable", middleware.AuthMiddleware(), func(c *gin.Context) {
+		upload.HandleResumableInit(c, minioClient, redisClient)
+	})
+
+	router.GET("/upload/video/resumable/:id", middleware.AuthMiddleware(), func(c *gin.Context) {
+		upload.HandleResumableStatus(c, redisClient)
+	})
+
+	router.PATCH("/upload/video/resumable/:id", middleware.AuthMiddleware(), func(c *gin.Context) {
+		upload.HandleResumableChunk(c, minioClient, redisClient, videoAPI, outbox)
+	})
+
+	router.DELETE("/upload/video/resumable/:id", middleware.AuthMiddleware(), func(c *gin.Context) {
+		upload.HandleResumableAbort(c, minioClient, redisClient)
+	})
+
+	router.GET("/videos/:id/status", middleware.AuthMiddleware(), func(c *gin.Context) {
+		status.HandleGetStatus(c, redisClient)
+	})
+
+	router.GET("/videos/:id/events", middleware.AuthMiddleware(), func(c *gin.Context) {
+		status.HandleStatusEvents(c, redisClient)
+	})
+
+	adminRoutes := router.Group("/admin", middleware.AuthMiddleware(), middleware.RequireRole("admin"))
+
+	adminRoutes.GET("/dlq", func(c *gin.Context) {
+		admin.HandleListDeadLetters(c, deadLetters)
+	})
+
+	adminRoutes.POST("/dlq/replay", func(c *gin.Context) {
+		admin.HandleReplayDeadLetters(c, deadLetters, redisClient)
+	})
+
+	adminRoutes.POST("/dlq/:job_id/replay", func(c *gin.Context) {
+		admin.HandleReplayDeadLetters(c, deadLetters, redisClient)
+	})
+
+	adminRoutes.DELETE("/dlq", func(c *gin.Context) {
+		admin.HandlePurgeDeadLetters(c, deadLetters)
+	})
+
+	adminRoutes.DELETE("/dlq/:job_id", func(c *gin.Context) {
+		admin.HandlePurgeDeadLetters(c, deadLetters)
 	})
 
 	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
+		c.JSON(200, gin.H{"status": "ok", "workers": consumer.Stats()})
 	})
 
-	log.Printf("🚀 Serviço de Upload iniciado na porta %s", cfg.ServerPort)
-	log.Fatal(router.Run(":" + cfg.ServerPort))
+	server := &http.Server{
+		Addr:    ":" + cfg.ServerPort,
+		Handler: router,
+	}
+
+	go func() {
+		log.Printf("🚀 Serviço de Upload iniciado na porta %s", cfg.ServerPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Erro no servidor HTTP:", err)
+		}
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	log.Printf("🛑 Recebido sinal de shutdown, encerrando (prazo de %s)...", cfg.ShutdownTimeout)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	// Para de consumir a fila e de aceitar uploads; os jobs e requisições em
+	// andamento são aguardados em paralelo até o mesmo prazo.
+	cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			// Conexões de longa duração (como os streams SSE) são encerradas à força.
+			log.Printf("⚠️ Encerrando conexões HTTP restantes: %v", err)
+			server.Close()
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		if err := consumer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+	}()
+
+	wg.Wait()
+
+	log.Println("👋 Serviço encerrado")
 }
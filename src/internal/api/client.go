@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"src/internal/models"
+	"time"
+)
+
+// VideoAPI reúne as chamadas feitas à API principal para registrar vídeos
+// e acompanhar o status de processamento.
+type VideoAPI interface {
+	CreateVideo(title, url string, userID uint, authToken string) (uint, error)
+	UpdateVideoStatus(videoID uint, status string, authToken string) error
+	UpdateVideoOutput(videoID uint, outputKey string, authToken string) error
+}
+
+type VideoCreateRequest struct {
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	UserID uint   `json:"id_user"`
+}
+
+type VideoCreateResponse struct {
+	ID uint `json:"id"`
+}
+
+// StatusError é devolvido quando a API responde com um status inesperado.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("API retornou status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("API retornou status %d: %s", e.StatusCode, e.Body)
+}
+
+type HTTPClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewHTTPClient(baseURL string) *HTTPClient {
+	return &HTTPClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func NewHTTPClientFromEnv() *HTTPClient {
+	apiBaseURL := os.Getenv("API_BASE_URL")
+	if apiBaseURL == "" {
+		apiBaseURL = "http://localhost:8000"
+	}
+	return NewHTTPClient(apiBaseURL)
+}
+
+func (c *HTTPClient) CreateVideo(title, url string, userID uint, authToken string) (uint, error) {
+	jsonData, err := json.Marshal(VideoCreateRequest{
+		Title:  title,
+		URL:    url,
+		UserID: userID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/v1/videos", c.baseURL), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", authToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var videoResp VideoCreateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&videoResp); err != nil {
+		return 0, err
+	}
+
+	return videoResp.ID, nil
+}
+
+func (c *HTTPClient) UpdateVideoStatus(videoID uint, status string, authToken string) error {
+	apiStatus := APIStatus(status)
+	if err := c.updateVideo(videoID, map[string]any{"status": apiStatus}, authToken); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Status atualizado para VideoID=%d: %s", videoID, apiStatus)
+	return nil
+}
+
+// UpdateVideoOutput registra no vídeo a chave do objeto gerado pelo
+// processamento no MinIO.
+func (c *HTTPClient) UpdateVideoOutput(videoID uint, outputKey string, authToken string) error {
+	if err := c.updateVideo(videoID, map[string]any{"output_key": outputKey}, authToken); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Saída registrada para VideoID=%d: %s", videoID, outputKey)
+	return nil
+}
+
+// updateVideo busca o vídeo e envia um PUT com os campos informados
+// sobrepostos ao título, URL e status atuais.
+func (c *HTTPClient) updateVideo(videoID uint, fields map[string]any, authToken string) error {
+	getURL := fmt.Sprintf("%s/api/v1/videos/%d", c.baseURL, videoID)
+	getReq, err := http.NewRequest("GET", getURL, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao criar requisição GET: %w", err)
+	}
+
+	if authToken != "" {
+		getReq.Header.Set("Authorization", authToken)
+	}
+
+	getResp, err := c.httpClient.Do(getReq)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar vídeo: %w", err)
+	}
+	defer getResp.Body.Close()
+
+	if getResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("erro ao buscar vídeo: %w", &StatusError{StatusCode: getResp.StatusCode})
+	}
+
+	var videoData map[string]any
+	if err := json.NewDecoder(getResp.Body).Decode(&videoData); err != nil {
+		return fmt.Errorf("erro ao decodificar resposta: %w", err)
+	}
+
+	updateData := map[string]any{
+		"title":  videoData["title"],
+		"url":    videoData["url"],
+		"status": videoData["status"],
+	}
+	if outputKey, ok := videoData["output_key"]; ok {
+		updateData["output_key"] = outputKey
+	}
+	for key, value := range fields {
+		updateData[key] = value
+	}
+
+	jsonData, err := json.Marshal(updateData)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/videos/%d", c.baseURL, videoID)
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if authToken != "" {
+		req.Header.Set("Authorization", authToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao fazer requisição: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// APIStatus converte o status interno do upload service para o status da API.
+func APIStatus(status string) string {
+	switch status {
+	case models.StatusPending:
+		return "pending"
+	case models.StatusProcessing:
+		return "processing"
+	case models.StatusCompleted:
+		return "processed"
+	case models.StatusFailed:
+		return "failed"
+	default:
+		return "pending"
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"src/internal/config"
+	"src/internal/models"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -39,15 +40,17 @@ func NewRedisClient(cfg *config.Config) (*RedisClient, error) {
 }
 
 type VideoCache struct {
-	ID          uint      `json:"id"`
-	Title       string    `json:"title"`
-	Status      string    `json:"status"`
-	UserID      uint      `json:"user_id"`
-	URL         string    `json:"url"`
-	Duration    int       `json:"duration,omitempty"`
-	Thumbnail   string    `json:"thumbnail,omitempty"`
-	ProcessedAt time.Time `json:"processed_at,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          uint                  `json:"id"`
+	Title       string                `json:"title"`
+	Status      string                `json:"status"`
+	UserID      uint                  `json:"user_id"`
+	URL         string                `json:"url"`
+	Duration    int                   `json:"duration,omitempty"`
+	Thumbnail   string                `json:"thumbnail,omitempty"`
+	Preview     string                `json:"preview,omitempty"`
+	Metadata    *models.VideoMetadata `json:"metadata,omitempty"`
+	ProcessedAt time.Time             `json:"processed_at,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
 }
 
 type UserSession struct {
@@ -60,25 +63,79 @@ type UserSession struct {
 
 type ProcessingStatus struct {
 	VideoID       uint      `json:"video_id"`
+	UserID        uint      `json:"user_id"`
+	JobID         string    `json:"job_id,omitempty"`
 	Status        string    `json:"status"`
 	Progress      int       `json:"progress"`
+	CurrentFrame  int       `json:"current_frame,omitempty"`
+	FrameCount    int       `json:"frame_count,omitempty"`
 	Message       string    `json:"message"`
+	Attempt       int       `json:"attempt,omitempty"`
+	MaxAttempts   int       `json:"max_attempts,omitempty"`
 	EstimatedTime int       `json:"estimated_time"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+type UploadPart struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+type UploadSession struct {
+	ID         string                    `json:"id"`
+	UserID     uint                      `json:"user_id"`
+	FileName   string                    `json:"file_name"`
+	ObjectName string                    `json:"object_name"`
+	UploadID   string                    `json:"upload_id"`
+	Size       int64                     `json:"size"`
+	Offset     int64                     `json:"offset"`
+	Parts      []UploadPart              `json:"parts"`
+	Options    *models.ExtractionOptions `json:"options,omitempty"`
+	// URL é preenchida quando o multipart já foi concluído no MinIO.
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OutboxEntry é um job aceito que ainda aguarda a confirmação de publicação
+// no RabbitMQ.
+type OutboxEntry struct {
+	Job        models.VideoProcessingJob `json:"job"`
+	EnqueuedAt time.Time                 `json:"enqueued_at"`
+	Attempts   int                       `json:"attempts"`
+}
+
 const (
 	VideoKeyPrefix      = "video:"
 	UserKeyPrefix       = "user:"
 	ProcessingKeyPrefix = "processing:"
 	SessionKeyPrefix    = "session:"
+	UploadKeyPrefix     = "upload:"
+	UploadLockPrefix    = "upload_lock:"
+
+	// Hash com as entradas do outbox, indexadas pelo ID do job. Não expira:
+	// as entradas só saem depois de publicadas.
+	OutboxKey = "outbox:jobs"
+)
+
+const (
+	ProcessingEventsChannelPrefix = "processing_events:"
 )
 
 const (
 	VideoTTL      = 1 * time.Hour
 	UserTTL       = 30 * time.Minute
-	ProcessingTTL = 10 * time.Minute
 	SessionTTL    = 24 * time.Hour
+	UploadTTL     = 24 * time.Hour
+	UploadLockTTL = 15 * time.Minute
+)
+
+// Status de jobs ainda em andamento (pending, processing, retrying) são
+// renovados a cada atualização e cobrem a espera na fila e os retries;
+// status finais duram tanto quanto as URLs pré-assinadas das saídas.
+const (
+	ProcessingTTL      = 24 * time.Hour
+	FinalProcessingTTL = 7 * 24 * time.Hour
 )
 
 func (r *RedisClient) SetVideo(ctx context.Context, video *VideoCache) error {
@@ -149,7 +206,53 @@ func (r *RedisClient) SetProcessingStatus(ctx context.Context, status *Processin
 		return fmt.Errorf("erro ao serializar status: %w", err)
 	}
 
-	return r.client.Set(ctx, key, data, ProcessingTTL).Err()
+	if err := r.client.Set(ctx, key, data, processingStatusTTL(status.Status)).Err(); err != nil {
+		return err
+	}
+
+	channel := fmt.Sprintf("%s%d", ProcessingEventsChannelPrefix, status.VideoID)
+	if err := r.client.Publish(ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("erro ao publicar status: %w", err)
+	}
+
+	return nil
+}
+
+func processingStatusTTL(status string) time.Duration {
+	if status == models.StatusCompleted || status == models.StatusFailed {
+		return FinalProcessingTTL
+	}
+	return ProcessingTTL
+}
+
+func (r *RedisClient) SubscribeProcessingStatus(ctx context.Context, videoID uint) (<-chan *ProcessingStatus, func() error, error) {
+	channel := fmt.Sprintf("%s%d", ProcessingEventsChannelPrefix, videoID)
+
+	pubsub := r.client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, fmt.Errorf("erro ao assinar status: %w", err)
+	}
+
+	updates := make(chan *ProcessingStatus)
+	go func() {
+		defer close(updates)
+		for msg := range pubsub.Channel() {
+			var status ProcessingStatus
+			if err := json.Unmarshal([]byte(msg.Payload), &status); err != nil {
+				log.Printf("Erro ao deserializar evento de status: %v", err)
+				continue
+			}
+
+			select {
+			case updates <- &status:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates, pubsub.Close, nil
 }
 
 func (r *RedisClient) GetProcessingStatus(ctx context.Context, videoID uint) (*ProcessingStatus, error) {
@@ -171,6 +274,93 @@ func (r *RedisClient) GetProcessingStatus(ctx context.Context, videoID uint) (*P
 	return &status, nil
 }
 
+func (r *RedisClient) SetUploadSession(ctx context.Context, session *UploadSession) error {
+	key := fmt.Sprintf("%s%s", UploadKeyPrefix, session.ID)
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar sessão de upload: %w", err)
+	}
+
+	return r.client.Set(ctx, key, data, UploadTTL).Err()
+}
+
+func (r *RedisClient) GetUploadSession(ctx context.Context, uploadID string) (*UploadSession, error) {
+	key := fmt.Sprintf("%s%s", UploadKeyPrefix, uploadID)
+
+	data, err := r.client.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao buscar sessão de upload: %w", err)
+	}
+
+	var session UploadSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar sessão de upload: %w", err)
+	}
+
+	return &session, nil
+}
+
+func (r *RedisClient) DeleteUploadSession(ctx context.Context, uploadID string) error {
+	key := fmt.Sprintf("%s%s", UploadKeyPrefix, uploadID)
+	return r.client.Del(ctx, key).Err()
+}
+
+// releaseLockScript só remove o lock se ele ainda pertencer ao token
+// informado, para que uma requisição cujo lock expirou não libere o lock de
+// outra.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func (r *RedisClient) AcquireUploadLock(ctx context.Context, uploadID, token string) (bool, error) {
+	key := fmt.Sprintf("%s%s", UploadLockPrefix, uploadID)
+	return r.client.SetNX(ctx, key, token, UploadLockTTL).Result()
+}
+
+func (r *RedisClient) ReleaseUploadLock(ctx context.Context, uploadID, token string) error {
+	key := fmt.Sprintf("%s%s", UploadLockPrefix, uploadID)
+	return releaseLockScript.Run(ctx, r.client, []string{key}, token).Err()
+}
+
+func (r *RedisClient) SaveOutboxEntry(ctx context.Context, entry *OutboxEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar entrada do outbox: %w", err)
+	}
+
+	return r.client.HSet(ctx, OutboxKey, entry.Job.ID, data).Err()
+}
+
+func (r *RedisClient) DeleteOutboxEntry(ctx context.Context, jobID string) error {
+	return r.client.HDel(ctx, OutboxKey, jobID).Err()
+}
+
+func (r *RedisClient) ListOutboxEntries(ctx context.Context) ([]OutboxEntry, error) {
+	values, err := r.client.HGetAll(ctx, OutboxKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar outbox: %w", err)
+	}
+
+	entries := make([]OutboxEntry, 0, len(values))
+	for jobID, data := range values {
+		var entry OutboxEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			log.Printf("Erro ao deserializar entrada %s do outbox: %v", jobID, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
 func (r *RedisClient) InvalidateVideo(ctx context.Context, videoID uint) error {
 	key := fmt.Sprintf("%s%d", VideoKeyPrefix, videoID)
 	return r.client.Del(ctx, key).Err()
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"src/internal/models"
+	"testing"
+	"time"
+)
+
+func TestProcessingStatusTTL(t *testing.T) {
+	tests := []struct {
+		status string
+		want   time.Duration
+	}{
+		{models.StatusPending, ProcessingTTL},
+		{models.StatusProcessing, ProcessingTTL},
+		{models.StatusRetrying, ProcessingTTL},
+		{models.StatusCompleted, FinalProcessingTTL},
+		{models.StatusFailed, FinalProcessingTTL},
+	}
+
+	for _, tt := range tests {
+		if got := processingStatusTTL(tt.status); got != tt.want {
+			t.Errorf("processingStatusTTL(%s) = %s, esperado %s", tt.status, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package config
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
 
 type Config struct {
 	MinioEndpoint  string
@@ -15,6 +20,12 @@ type Config struct {
 	APIBaseURL string
 
 	ServerPort string
+
+	WorkDir string
+
+	WorkerPoolSize int
+
+	ShutdownTimeout time.Duration
 }
 
 func LoadConfig() *Config {
@@ -31,6 +42,12 @@ func LoadConfig() *Config {
 		APIBaseURL: getEnv("API_BASE_URL", "http://localhost:8080"),
 
 		ServerPort: getEnv("SERVER_PORT", "8081"),
+
+		WorkDir: getEnv("WORK_DIR", filepath.Join(os.TempDir(), "video-processing")),
+
+		WorkerPoolSize: getEnvInt("WORKER_POOL_SIZE", 2),
+
+		ShutdownTimeout: time.Duration(getEnvInt("SHUTDOWN_TIMEOUT", 30)) * time.Second,
 	}
 }
 
@@ -40,3 +57,11 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -38,8 +38,45 @@ func AuthMiddleware() gin.HandlerFunc {
 					c.Set("userID", userIDInt)
 				}
 			}
+			c.Set("roles", claimRoles(claims))
 		}
 
 		c.Next()
 	}
 }
+
+// RequireRole deve ser usado depois de AuthMiddleware e só permite
+// requisições cujo token contenha o papel informado.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles, _ := c.Get("roles")
+		userRoles, _ := roles.([]string)
+
+		for _, userRole := range userRoles {
+			if userRole == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+	}
+}
+
+// claimRoles lê os papéis do claim "roles" (lista) ou "role" (texto).
+func claimRoles(claims jwt.MapClaims) []string {
+	var roles []string
+
+	if list, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range list {
+			if name, ok := role.(string); ok {
+				roles = append(roles, name)
+			}
+		}
+	}
+	if role, ok := claims["role"].(string); ok && role != "" {
+		roles = append(roles, role)
+	}
+
+	return roles
+}
@@ -3,24 +3,132 @@ package models
 import "time"
 
 type VideoProcessingJob struct {
-	ID        string    `json:"id"`
-	VideoID   uint      `json:"video_id"`
-	UserID    uint      `json:"user_id"`
-	VideoURL  string    `json:"video_url"`
-	FileName  string    `json:"file_name"`
-	Status    string    `json:"status"`
-	AuthToken string    `json:"auth_token,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string             `json:"id"`
+	VideoID   uint               `json:"video_id"`
+	UserID    uint               `json:"user_id"`
+	VideoURL  string             `json:"video_url"`
+	FileName  string             `json:"file_name"`
+	Status    string             `json:"status"`
+	Options   *ExtractionOptions `json:"options,omitempty"`
+	AuthToken string             `json:"auth_token,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+
+	// Preenchidos quando o job falha definitivamente e vai para a DLQ.
+	Attempts   int    `json:"attempts,omitempty"`
+	Error      string `json:"error,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+}
+
+type ExtractionOptions struct {
+	Mode           string  `json:"mode,omitempty"`
+	FPS            float64 `json:"fps,omitempty"`
+	Interval       float64 `json:"interval,omitempty"`
+	SceneThreshold float64 `json:"scene_threshold,omitempty"`
+	StartTime      float64 `json:"start_time,omitempty"`
+	EndTime        float64 `json:"end_time,omitempty"`
+	MaxFrames      int     `json:"max_frames,omitempty"`
+	Format         string  `json:"format,omitempty"`
+	Quality        int     `json:"quality,omitempty"`
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+
+	Steps []string `json:"steps,omitempty"`
+
+	ContactSheet *ContactSheetOptions `json:"contact_sheet,omitempty"`
+	Transcode    *TranscodeOptions    `json:"transcode,omitempty"`
+	Preview      *PreviewOptions      `json:"preview,omitempty"`
+	Audio        *AudioOptions        `json:"audio,omitempty"`
+}
+
+type ContactSheetOptions struct {
+	Columns    int  `json:"columns,omitempty"`
+	Rows       int  `json:"rows,omitempty"`
+	ThumbWidth int  `json:"thumb_width,omitempty"`
+	Timestamps bool `json:"timestamps,omitempty"`
+}
+
+type VideoMetadata struct {
+	Duration    float64      `json:"duration"`
+	Container   string       `json:"container"`
+	VideoCodec  string       `json:"video_codec"`
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	FrameRate   float64      `json:"frame_rate"`
+	Bitrate     int64        `json:"bitrate,omitempty"`
+	Rotation    int          `json:"rotation,omitempty"`
+	AudioTracks []AudioTrack `json:"audio_tracks,omitempty"`
+}
+
+type AudioTrack struct {
+	Index      int    `json:"index"`
+	Codec      string `json:"codec"`
+	Channels   int    `json:"channels"`
+	SampleRate int    `json:"sample_rate"`
+	Bitrate    int64  `json:"bitrate,omitempty"`
+	Language   string `json:"language,omitempty"`
+}
+
+type TranscodeOptions struct {
+	HLS             bool     `json:"hls,omitempty"`
+	DASH            bool     `json:"dash,omitempty"`
+	Renditions      []string `json:"renditions,omitempty"`
+	SegmentDuration int      `json:"segment_duration,omitempty"`
+}
+
+type PreviewOptions struct {
+	Format          string  `json:"format,omitempty"`
+	Segments        int     `json:"segments,omitempty"`
+	SegmentDuration float64 `json:"segment_duration,omitempty"`
+	Width           int     `json:"width,omitempty"`
+	FPS             int     `json:"fps,omitempty"`
+}
+
+type AudioOptions struct {
+	Format   string `json:"format,omitempty"`
+	Bitrate  int    `json:"bitrate,omitempty"`
+	Waveform bool   `json:"waveform,omitempty"`
+	Peaks    bool   `json:"peaks,omitempty"`
 }
 
 const (
 	StatusPending    = "pending"
 	StatusProcessing = "processing"
+	StatusRetrying   = "retrying"
 	StatusCompleted  = "completed"
 	StatusFailed     = "failed"
 )
 
+const (
+	ModeFPS       = "fps"
+	ModeScene     = "scene"
+	ModeKeyframes = "keyframes"
+	ModeAudio     = "audio"
+)
+
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+const (
+	PreviewGIF  = "gif"
+	PreviewMP4  = "mp4"
+	PreviewWebM = "webm"
+)
+
+const (
+	AudioMP3  = "mp3"
+	AudioAAC  = "aac"
+	AudioOpus = "opus"
+	AudioWAV  = "wav"
+)
+
 const (
 	InputProcessingQueue = "input_processing_queue"
+	DeadLetterQueue      = "input_processing_queue.dlq"
+
+	RetryExchange      = "video_processing.retry"
+	DeadLetterExchange = "video_processing.dlx"
 )
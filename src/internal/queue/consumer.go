@@ -1,48 +1,143 @@
 package queue
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
+	"math"
 	"os"
-	"path/filepath"
+	"src/internal/api"
+	"src/internal/cache"
 	"src/internal/models"
 	"src/internal/services/video_processing"
 	"src/internal/storage"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	// Prazo máximo aceito pelo MinIO para URLs pré-assinadas.
+	outputURLExpiry = 7 * 24 * time.Hour
+
+	// O upload de cada saída tem um prazo mínimo mais o tempo de enviar o
+	// arquivo a minUploadRate, para que um MinIO travado não segure o worker.
+	minUploadTimeout = 1 * time.Minute
+	minUploadRate    = 1 << 20 // bytes por segundo
+
+	// Tempo dado aos jobs para devolverem as mensagens à fila depois que o
+	// contexto deles é cancelado no shutdown (o ffmpeg recebe SIGTERM e, após
+	// 10s, SIGKILL).
+	abortGrace = 15 * time.Second
+)
+
 type Consumer struct {
-	channel     *amqp.Channel
+	client      *RabbitMQClient
 	processor   *video_processing.Processor
-	minioClient *storage.MinioClient
-	apiBaseURL  string
+	store       storage.ObjectStore
+	redisClient *cache.RedisClient
+	videoAPI    api.VideoAPI
+	publisher   channelPublisher
+	// after aguarda o atraso entre as tentativas de publicação.
+	after func(d time.Duration) <-chan time.Time
+
+	tag     string
+	workers int
+	// slots limita os jobs simultâneos a workers, somando os de todos os
+	// canais: jobs de um canal que caiu ainda ocupam o pool até terminarem.
+	slots     chan struct{}
+	inFlight  atomic.Int32
+	workersWG sync.WaitGroup
+	mu        sync.Mutex
+	stopped   bool
+
+	// jobsCtx é o contexto de todos os jobs, cancelado quando o prazo de
+	// encerramento expira; jobs interrompidos voltam para a fila em vez de
+	// serem marcados como falhos.
+	jobsCtx   context.Context
+	abortJobs context.CancelFunc
 }
 
-func NewConsumer(channel *amqp.Channel, processor *video_processing.Processor, minioClient *storage.MinioClient) *Consumer {
-	apiBaseURL := os.Getenv("API_BASE_URL")
-	if apiBaseURL == "" {
-		apiBaseURL = "http://localhost:8000"
-	}
+// WorkerStats resume a ocupação do pool de workers do consumer.
+type WorkerStats struct {
+	PoolSize int `json:"pool_size"`
+	InFlight int `json:"in_flight"`
+	Idle     int `json:"idle"`
+}
 
+func NewConsumer(client *RabbitMQClient, processor *video_processing.Processor, store storage.ObjectStore, redisClient *cache.RedisClient, videoAPI api.VideoAPI, workers int) *Consumer {
+	if workers <= 0 {
+		workers = 1
+	}
+	jobsCtx, abortJobs := context.WithCancel(context.Background())
 	return &Consumer{
-		channel:     channel,
+		client:      client,
 		processor:   processor,
-		minioClient: minioClient,
-		apiBaseURL:  apiBaseURL,
+		store:       store,
+		redisClient: redisClient,
+		videoAPI:    videoAPI,
+		publisher:   client,
+		after:       time.After,
+		tag:         consumerTag(),
+		workers:     workers,
+		slots:       make(chan struct{}, workers),
+		jobsCtx:     jobsCtx,
+		abortJobs:   abortJobs,
+	}
+}
+
+func consumerTag() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
 	}
+	return fmt.Sprintf("video-processing-%s-%d", hostname, os.Getpid())
 }
 
+func (c *Consumer) Stats() WorkerStats {
+	inFlight := int(c.inFlight.Load())
+	return WorkerStats{
+		PoolSize: c.workers,
+		InFlight: inFlight,
+		Idle:     c.workers - inFlight,
+	}
+}
+
+// StartProcessing consome a fila até ctx ser cancelado. Se o canal cair, os
+// jobs das entregas dele são abandonados e o consumer se inscreve de novo,
+// sem esperá-los, no canal aberto pela reconexão do RabbitMQClient.
 func (c *Consumer) StartProcessing(ctx context.Context) error {
-	msgs, err := c.channel.Consume(
+	channel := c.client.GetChannel()
+	for {
+		err := c.consume(ctx, channel)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("⚠️ Consumer interrompido: %v. Aguardando reconexão ao RabbitMQ...", err)
+
+		channel, err = c.client.WaitForChannel(ctx, channel)
+		if err != nil {
+			return err
+		}
+		log.Println("🔁 Consumer inscrito novamente na fila de processamento")
+	}
+}
+
+// consume se inscreve na fila pelo canal informado e processa as entregas
+// até o canal fechar ou ctx ser cancelado.
+func (c *Consumer) consume(ctx context.Context, channel amqpChannel) error {
+	// O prefetch igual ao tamanho do pool impede que o RabbitMQ entregue
+	// mais mensagens do que os workers conseguem processar.
+	if err := channel.Qos(c.workers, 0, false); err != nil {
+		return fmt.Errorf("erro ao configurar prefetch: %w", err)
+	}
+
+	msgs, err := channel.Consume(
 		models.InputProcessingQueue,
-		"",
+		c.tag,
 		false,
 		false,
 		false,
@@ -50,205 +145,530 @@ func (c *Consumer) StartProcessing(ctx context.Context) error {
 		nil,
 	)
 	if err != nil {
-		return err
+		return fmt.Errorf("erro ao consumir fila: %w", err)
 	}
 
-	log.Println("🎬 Consumer iniciado - aguardando jobs de processamento...")
+	log.Printf("🎬 Consumer iniciado com %d workers - aguardando jobs de processamento...", c.workers)
 
-	for {
+	// deliveriesCtx é o contexto dos jobs deste canal. Se o canal cair, o
+	// RabbitMQ devolve à fila as entregas sem ack e elas não podem mais ser
+	// confirmadas, então os jobs são cancelados em vez de terminarem e
+	// publicarem retries em duplicidade.
+	deliveriesCtx, abandon := context.WithCancel(c.jobsCtx)
+	closed := channel.NotifyClose(make(chan *amqp.Error, 1))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case msg := <-msgs:
-			go c.handleMessage(msg)
+			if err := channel.Cancel(c.tag, false); err != nil {
+				log.Printf("Erro ao cancelar consumer: %v", err)
+			}
+		case <-closed:
+			abandon()
+		case <-stop:
 		}
+	}()
+
+	c.runWorkers(ctx, deliveriesCtx, msgs)
+
+	if ctx.Err() != nil {
+		// No encerramento os jobs em andamento seguem até Shutdown.
+		go func() {
+			<-c.jobsCtx.Done()
+			abandon()
+		}()
+		return ctx.Err()
 	}
+	abandon()
+	return fmt.Errorf("canal de mensagens do RabbitMQ foi fechado")
 }
 
-func (c *Consumer) handleMessage(msg amqp.Delivery) {
-	var job models.VideoProcessingJob
-	if err := json.Unmarshal(msg.Body, &job); err != nil {
-		log.Printf("Erro ao deserializar job: %v", err)
-		if nackErr := msg.Nack(false, false); nackErr != nil {
-			log.Printf("Erro ao fazer Nack: %v", nackErr)
+// runWorkers distribui as entregas entre no máximo c.workers jobs
+// simultâneos, executados com deliveriesCtx. Retorna quando msgs é fechado,
+// ctx é cancelado ou deliveriesCtx é cancelado pela queda do canal, sem
+// esperar os jobs em andamento; use Shutdown para aguardá-los.
+func (c *Consumer) runWorkers(ctx, deliveriesCtx context.Context, msgs <-chan amqp.Delivery) {
+	for {
+		// O worker é reservado antes de receber a entrega, para que as
+		// mensagens além do pool continuem no RabbitMQ.
+		select {
+		case <-ctx.Done():
+			return
+		case <-deliveriesCtx.Done():
+			return
+		case c.slots <- struct{}{}:
 		}
-		return
-	}
 
-	log.Printf("🎬 Processando job: VideoID=%d, UserID=%d", job.VideoID, job.UserID)
-
-	maxRetries := 3
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		log.Printf("🔄 Tentativa %d/%d para VideoID=%d", attempt, maxRetries, job.VideoID)
-
-		err := c.processAndSaveVideo(&job)
-		if err == nil {
-			if updateErr := c.updateVideoStatus(job.VideoID, models.StatusCompleted, job.AuthToken); updateErr != nil {
-				log.Printf("Erro ao atualizar status para completed: %v", updateErr)
-			}
-			if ackErr := msg.Ack(false); ackErr != nil {
-				log.Printf("Erro ao fazer Ack: %v", ackErr)
+		var msg amqp.Delivery
+		var ok bool
+		select {
+		case <-ctx.Done():
+		case <-deliveriesCtx.Done():
+		case msg, ok = <-msgs:
+		}
+		if !ok {
+			<-c.slots
+			return
+		}
+		if ctx.Err() != nil || deliveriesCtx.Err() != nil || !c.startWorker() {
+			<-c.slots
+			if err := msg.Nack(false, true); err != nil {
+				log.Printf("Erro ao fazer Nack: %v", err)
 			}
 			return
 		}
 
-		log.Printf("Tentativa %d falhou para VideoID=%d: %v", attempt, job.VideoID, err)
+		go func() {
+			defer c.workersWG.Done()
+			defer func() { <-c.slots }()
 
-		if attempt < maxRetries {
-			waitTime := time.Duration(attempt*attempt) * time.Second
-			log.Printf("Aguardando %v antes da próxima tentativa...", waitTime)
-			time.Sleep(waitTime)
-		}
+			c.inFlight.Add(1)
+			c.handleMessage(deliveriesCtx, msg)
+			c.inFlight.Add(-1)
+		}()
 	}
+}
 
-	log.Printf("Todas as %d tentativas falharam para VideoID=%d", maxRetries, job.VideoID)
-	if updateErr := c.updateVideoStatus(job.VideoID, models.StatusFailed, job.AuthToken); updateErr != nil {
-		log.Printf("Erro ao atualizar status para failed: %v", updateErr)
+// startWorker registra um job em andamento, a menos que o Shutdown já tenha
+// começado.
+func (c *Consumer) startWorker() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return false
 	}
+	c.workersWG.Add(1)
+	return true
+}
 
-	if ackErr := msg.Ack(false); ackErr != nil {
-		log.Printf("Erro ao fazer Ack: %v", ackErr)
+// Shutdown aguarda os jobs em andamento até o prazo de ctx. Se o prazo
+// expirar, cancela o contexto dos jobs (interrompendo o ffmpeg e as chamadas
+// ao storage) e os jobs inacabados voltam para a fila.
+// Deve ser chamado depois de cancelar o contexto de StartProcessing.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.workersWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("✅ Jobs em andamento finalizados")
+		// Sem jobs em andamento, o cancelamento apenas libera os contextos
+		// das entregas.
+		c.abortJobs()
+		return nil
+	case <-ctx.Done():
+	}
+
+	inFlight := c.Stats().InFlight
+	log.Printf("⏰ Prazo de encerramento excedido com %d jobs em andamento, interrompendo ffmpeg...", inFlight)
+
+	c.abortJobs()
+
+	select {
+	case <-done:
+	case <-time.After(abortGrace):
+		log.Println("⚠️ Workers não finalizaram após interromper o ffmpeg")
 	}
+
+	return fmt.Errorf("prazo de encerramento excedido com %d jobs em andamento", inFlight)
 }
 
-func (c *Consumer) updateVideoStatus(videoID uint, status string, authToken string) error {
-	getURL := fmt.Sprintf("%s/api/v1/videos/%d", c.apiBaseURL, videoID)
-	getReq, err := http.NewRequest("GET", getURL, nil)
-	if err != nil {
-		return fmt.Errorf("erro ao criar requisição GET: %w", err)
+func (c *Consumer) aborted() bool {
+	return c.jobsCtx.Err() != nil
+}
+
+// requeueJob devolve à fila um job interrompido pelo encerramento do
+// serviço, para que outra instância o processe do início.
+func (c *Consumer) requeueJob(msg amqp.Delivery, job *models.VideoProcessingJob) {
+	log.Printf("↩️ Job devolvido à fila pelo encerramento do serviço: VideoID=%d", job.VideoID)
+	c.setProcessingStatus(job, &cache.ProcessingStatus{
+		Status:  models.StatusPending,
+		Message: "Processamento interrompido pelo encerramento do serviço, aguardando nova tentativa",
+	})
+	if err := msg.Nack(false, true); err != nil {
+		log.Printf("Erro ao fazer Nack: %v", err)
 	}
+}
 
-	if authToken != "" {
-		getReq.Header.Set("Authorization", authToken)
+// abandonJob descarta um job cujo canal caiu durante o processamento. A
+// entrega não pode mais ser confirmada e o RabbitMQ já a devolveu à fila,
+// então nada é publicado nem confirmado.
+func (c *Consumer) abandonJob(job *models.VideoProcessingJob) {
+	log.Printf("↩️ Canal do RabbitMQ fechado durante o processamento, job será entregue novamente: VideoID=%d", job.VideoID)
+	c.setProcessingStatus(job, &cache.ProcessingStatus{
+		Status:  models.StatusPending,
+		Message: "Processamento interrompido pela queda da conexão com a fila, aguardando nova tentativa",
+	})
+}
+
+// handleMessage processa uma entrega com o contexto ctx. Falhas transitórias
+// são reenviadas para a fila de retry correspondente à tentativa; em falhas
+// permanentes, ou após maxRetries tentativas, o job é enviado para a DLQ com
+// o motivo e a classe da falha.
+func (c *Consumer) handleMessage(ctx context.Context, msg amqp.Delivery) {
+	var job models.VideoProcessingJob
+	if err := json.Unmarshal(msg.Body, &job); err != nil {
+		log.Printf("Erro ao deserializar job: %v", err)
+		c.deadLetter(ctx, msg, msg.Body, fmt.Sprintf("mensagem inválida: %v", err), video_processing.ErrorInvalidInput, 1)
+		return
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	getResp, err := client.Do(getReq)
-	if err != nil {
-		return fmt.Errorf("erro ao buscar vídeo: %w", err)
+	attempt := deliveryAttempt(msg.Headers)
+	log.Printf("🎬 Processando job: VideoID=%d, UserID=%d, tentativa %d/%d", job.VideoID, job.UserID, attempt, maxRetries)
+
+	c.setProcessingStatus(&job, &cache.ProcessingStatus{
+		Status:  models.StatusProcessing,
+		Message: fmt.Sprintf("Processando (tentativa %d/%d)", attempt, maxRetries),
+		Attempt: attempt,
+	})
+
+	result, err := c.processAndSaveVideo(ctx, &job, attempt)
+	if err == nil {
+		c.setProcessingStatus(&job, &cache.ProcessingStatus{
+			Status:     models.StatusCompleted,
+			Progress:   100,
+			FrameCount: result.FrameCount,
+			Message:    result.Message,
+			Attempt:    attempt,
+		})
+		if result.OutputKey != "" {
+			if updateErr := c.videoAPI.UpdateVideoOutput(job.VideoID, result.OutputKey, job.AuthToken); updateErr != nil {
+				log.Printf("Erro ao registrar saída do vídeo na API: %v", updateErr)
+			}
+		}
+		if updateErr := c.videoAPI.UpdateVideoStatus(job.VideoID, models.StatusCompleted, job.AuthToken); updateErr != nil {
+			log.Printf("Erro ao atualizar status para completed: %v", updateErr)
+		}
+		if ackErr := msg.Ack(false); ackErr != nil {
+			log.Printf("Erro ao fazer Ack: %v", ackErr)
+		}
+		return
+	}
+
+	if c.aborted() {
+		c.requeueJob(msg, &job)
+		return
 	}
-	defer getResp.Body.Close()
+	if ctx.Err() != nil {
+		c.abandonJob(&job)
+		return
+	}
+
+	class := video_processing.ErrorClassOf(err)
+	log.Printf("Tentativa %d falhou para VideoID=%d (%s): %v", attempt, job.VideoID, class, err)
 
-	if getResp.StatusCode != http.StatusOK {
-		return fmt.Errorf("erro ao buscar vídeo: API retornou status %d", getResp.StatusCode)
+	if class.Retryable() && attempt < maxRetries {
+		c.scheduleRetry(ctx, msg, &job, attempt, err)
+		return
 	}
 
-	var videoData map[string]any
-	if err := json.NewDecoder(getResp.Body).Decode(&videoData); err != nil {
-		return fmt.Errorf("erro ao decodificar resposta: %w", err)
+	message := fmt.Sprintf("Todas as %d tentativas falharam: %v", maxRetries, err)
+	if class.Retryable() {
+		log.Printf("Todas as %d tentativas falharam para VideoID=%d", maxRetries, job.VideoID)
+	} else {
+		log.Printf("Falha permanente (%s) para VideoID=%d, sem novas tentativas", class, job.VideoID)
+		message = fmt.Sprintf("Falha permanente (%s): %v", class, err)
 	}
 
-	var apiStatus string
-	switch status {
-	case models.StatusPending:
-		apiStatus = "pending"
-	case models.StatusProcessing:
-		apiStatus = "processing"
-	case models.StatusCompleted:
-		apiStatus = "processed"
-	case models.StatusFailed:
-		apiStatus = "failed"
-	default:
-		apiStatus = "pending"
+	c.setProcessingStatus(&job, &cache.ProcessingStatus{
+		Status:  models.StatusFailed,
+		Message: message,
+		Attempt: attempt,
+	})
+	if updateErr := c.videoAPI.UpdateVideoStatus(job.VideoID, models.StatusFailed, job.AuthToken); updateErr != nil {
+		log.Printf("Erro ao atualizar status para failed: %v", updateErr)
 	}
 
-	updateData := map[string]any{
-		"title":  videoData["title"],
-		"url":    videoData["url"],
-		"status": apiStatus,
+	job.Status = models.StatusFailed
+	job.Attempts = attempt
+	job.Error = err.Error()
+	job.ErrorClass = string(class)
+	job.UpdatedAt = time.Now()
+
+	body, marshalErr := json.Marshal(&job)
+	if marshalErr != nil {
+		body = msg.Body
 	}
+	c.deadLetter(ctx, msg, body, err.Error(), class, attempt)
+}
 
-	jsonData, err := json.Marshal(updateData)
-	if err != nil {
-		return fmt.Errorf("erro ao serializar dados: %w", err)
+// publishWithBackoff publica msg repetindo as falhas com backoff
+// exponencial, para que uma indisponibilidade do RabbitMQ não devolva a
+// entrega à fila imediatamente e a faça ser reprocessada sem pausa. Desiste
+// após publishAttempts tentativas ou quando ctx é cancelado.
+func (c *Consumer) publishWithBackoff(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	delay := publishRetryMinDelay
+	for attempt := 1; ; attempt++ {
+		err := c.publisher.Publish(exchange, key, false, false, msg)
+		if err == nil || attempt == publishAttempts {
+			return err
+		}
+
+		log.Printf("⚠️ Tentativa %d de publicação em %s falhou: %v (nova tentativa em %s)", attempt, exchange, err, delay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-c.after(delay):
+		}
+
+		delay *= 2
+		if delay > publishRetryMaxDelay {
+			delay = publishRetryMaxDelay
+		}
 	}
+}
 
-	url := fmt.Sprintf("%s/api/v1/videos/%d", c.apiBaseURL, videoID)
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+// scheduleRetry publica a mensagem na fila de atraso da tentativa e confirma
+// a entrega atual. Os cabeçalhos originais são mantidos para que o x-death
+// continue contando as passagens pelas filas de retry.
+func (c *Consumer) scheduleRetry(ctx context.Context, msg amqp.Delivery, job *models.VideoProcessingJob, attempt int, cause error) {
+	delay, routingKey := retryDelay(attempt)
+
+	err := c.publishWithBackoff(ctx, models.RetryExchange, routingKey, amqp.Publishing{
+		Headers:      msg.Headers,
+		ContentType:  msg.ContentType,
+		DeliveryMode: amqp.Persistent,
+		Body:         msg.Body,
+	})
 	if err != nil {
-		return fmt.Errorf("erro ao criar requisição: %w", err)
+		log.Printf("Erro ao agendar nova tentativa para VideoID=%d: %v", job.VideoID, err)
+		if nackErr := msg.Nack(false, true); nackErr != nil {
+			log.Printf("Erro ao fazer Nack: %v", nackErr)
+		}
+		return
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	log.Printf("⏳ Nova tentativa de VideoID=%d agendada em %v", job.VideoID, delay)
+	c.setProcessingStatus(job, &cache.ProcessingStatus{
+		Status:        models.StatusRetrying,
+		Message:       fmt.Sprintf("Tentativa %d falhou: %v", attempt, cause),
+		Attempt:       attempt,
+		EstimatedTime: int(delay.Seconds()),
+	})
 
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
+	if ackErr := msg.Ack(false); ackErr != nil {
+		log.Printf("Erro ao fazer Ack: %v", ackErr)
 	}
+}
 
-	resp, err := client.Do(req)
+// deadLetter envia o job para a DLQ com o motivo e a classe da falha nos
+// cabeçalhos e confirma a entrega atual.
+func (c *Consumer) deadLetter(ctx context.Context, msg amqp.Delivery, body []byte, reason string, class video_processing.ErrorClass, attempts int) {
+	headers := amqp.Table{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers[headerFailureReason] = reason
+	headers[headerErrorClass] = string(class)
+	headers[headerFailedAt] = time.Now().UTC().Format(time.RFC3339)
+	headers[headerAttempts] = int32(attempts)
+
+	err := c.publishWithBackoff(ctx, models.DeadLetterExchange, models.InputProcessingQueue, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
 	if err != nil {
-		return fmt.Errorf("erro ao fazer requisição: %w", err)
+		log.Printf("Erro ao enviar job para a DLQ: %v", err)
+		if nackErr := msg.Nack(false, true); nackErr != nil {
+			log.Printf("Erro ao fazer Nack: %v", nackErr)
+		}
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API retornou status %d", resp.StatusCode)
+	log.Printf("🪦 Job enviado para a DLQ: %s", reason)
+	if ackErr := msg.Ack(false); ackErr != nil {
+		log.Printf("Erro ao fazer Ack: %v", ackErr)
 	}
+}
 
-	log.Printf("✅ Status atualizado para VideoID=%d: %s", videoID, apiStatus)
-	return nil
+func (c *Consumer) setProcessingStatus(job *models.VideoProcessingJob, processingStatus *cache.ProcessingStatus) {
+	if c.redisClient == nil {
+		return
+	}
+
+	processingStatus.VideoID = job.VideoID
+	processingStatus.UserID = job.UserID
+	processingStatus.JobID = job.ID
+	processingStatus.MaxAttempts = maxRetries
+	processingStatus.UpdatedAt = time.Now()
+
+	if err := c.redisClient.SetProcessingStatus(context.Background(), processingStatus); err != nil {
+		log.Printf("Erro ao salvar status de processamento no cache: %v", err)
+	}
 }
 
-func (c *Consumer) processAndSaveVideo(job *models.VideoProcessingJob) error {
-	if err := c.updateVideoStatus(job.VideoID, models.StatusProcessing, job.AuthToken); err != nil {
-		log.Printf("Erro ao atualizar status para processing: %v", err)
+func (c *Consumer) processAndSaveVideo(ctx context.Context, job *models.VideoProcessingJob, attempt int) (*video_processing.ProcessingResult, error) {
+	// Sem a API não há como registrar o resultado, então o job falha antes
+	// de baixar o vídeo. Um token recusado não impede o processamento: as
+	// saídas continuam sendo salvas e o status fica disponível no cache.
+	if err := c.videoAPI.UpdateVideoStatus(job.VideoID, models.StatusProcessing, job.AuthToken); err != nil {
+		if !isAuthError(err) {
+			return nil, fmt.Errorf("erro ao atualizar status para processing: %w", apiError(err))
+		}
+		log.Printf("⚠️ Token recusado pela API ao marcar VideoID=%d como processing, seguindo com o processamento: %v", job.VideoID, err)
 	}
 
-	result := c.processor.ProcessVideo(job)
+	result := c.processor.ProcessVideo(ctx, job, func(progress video_processing.Progress) {
+		c.setProcessingStatus(job, &cache.ProcessingStatus{
+			Status:        models.StatusProcessing,
+			Progress:      progress.Percent,
+			CurrentFrame:  progress.Frame,
+			Message:       fmt.Sprintf("Extraindo frames (%d%%)", progress.Percent),
+			Attempt:       attempt,
+			EstimatedTime: progress.EstimatedTime,
+		})
+	})
+	defer c.processor.ReleaseWorkDir(result)
+
 	log.Printf("🔎 Resultado do processamento: Status=%s, Message=%s, ProcessedAt=%s, ZipPath=%s, FrameCount=%d, Images=%v",
 		result.Status, result.Message, result.ProcessedAt.Format("2006-01-02 15:04:05"), result.ZipPath, result.FrameCount, result.Images)
 
+	c.cacheVideoMetadata(job, result)
+
 	if result.Status == models.StatusCompleted {
-		return c.saveProcessedVideo(job, result)
+		return result, c.saveProcessedVideo(ctx, job, result)
 	}
 
-	return fmt.Errorf("processamento falhou: %s", result.Message)
+	class := result.ErrorClass
+	if class == "" {
+		class = video_processing.ErrorInternal
+	}
+	return result, video_processing.NewProcessingError(class, fmt.Errorf("processamento falhou: %s", result.Message))
 }
 
-func (c *Consumer) saveProcessedVideo(job *models.VideoProcessingJob, result *video_processing.ProcessingResult) error {
-	if result.ZipPath != "" {
-		zipFilePath := filepath.Join("outputs", result.ZipPath)
+func (c *Consumer) cacheVideoMetadata(job *models.VideoProcessingJob, result *video_processing.ProcessingResult) {
+	if c.redisClient == nil || result.VideoInfo == nil {
+		return
+	}
 
-		if _, err := os.Stat(zipFilePath); os.IsNotExist(err) {
-			return fmt.Errorf("arquivo ZIP não encontrado: %s", zipFilePath)
-		}
+	ctx := context.Background()
 
-		zipFile, err := os.Open(zipFilePath)
-		if err != nil {
-			return fmt.Errorf("erro ao abrir arquivo ZIP: %w", err)
+	video, err := c.redisClient.GetVideo(ctx, job.VideoID)
+	if err != nil {
+		log.Printf("Erro ao buscar vídeo no cache: %v", err)
+	}
+	if video == nil {
+		video = &cache.VideoCache{
+			ID:        job.VideoID,
+			Title:     job.FileName,
+			UserID:    job.UserID,
+			URL:       job.VideoURL,
+			CreatedAt: job.CreatedAt,
 		}
-		defer zipFile.Close()
+	}
 
-		fileInfo, err := zipFile.Stat()
-		if err != nil {
-			return fmt.Errorf("erro ao obter informações do arquivo: %w", err)
-		}
+	video.Status = result.Status
+	video.Duration = int(math.Round(result.VideoInfo.Duration))
+	video.Metadata = result.VideoInfo
+	video.ProcessedAt = result.ProcessedAt
 
-		objectName := fmt.Sprintf("%d/outputs/%s", job.UserID, result.ZipPath)
+	if err := c.redisClient.SetVideo(ctx, video); err != nil {
+		log.Printf("Erro ao salvar metadados do vídeo no cache: %v", err)
+	}
+}
 
-		_, err = c.minioClient.UploadFile(context.Background(), objectName, zipFile, fileInfo.Size())
-		if err != nil {
-			return fmt.Errorf("erro ao salvar arquivo ZIP no MinIO: %w", err)
-		}
+// cacheOutputURL registra no cache do vídeo uma URL pré-assinada para as
+// saídas exibidas nas listagens (poster e preview).
+func (c *Consumer) cacheOutputURL(ctx context.Context, job *models.VideoProcessingJob, output video_processing.OutputFile) {
+	if c.redisClient == nil {
+		return
+	}
+
+	outputURL, err := c.store.GetFileURL(ctx, output.ObjectName, outputURLExpiry)
+	if err != nil {
+		log.Printf("Erro ao gerar URL de %s: %v", output.Kind, err)
+		return
+	}
+
+	video, err := c.redisClient.GetVideo(ctx, job.VideoID)
+	if err != nil || video == nil {
+		log.Printf("Vídeo %d não encontrado no cache para registrar %s", job.VideoID, output.Kind)
+		return
+	}
+
+	switch output.Kind {
+	case video_processing.OutputPoster:
+		video.Thumbnail = outputURL
+	case video_processing.OutputPreview:
+		video.Preview = outputURL
+	}
+
+	if err := c.redisClient.SetVideo(ctx, video); err != nil {
+		log.Printf("Erro ao salvar %s no cache: %v", output.Kind, err)
+	}
+}
 
-		log.Printf("✅ Arquivo ZIP salvo no MinIO: %s (frames: %d)", objectName, result.FrameCount)
+func (c *Consumer) saveProcessedVideo(ctx context.Context, job *models.VideoProcessingJob, result *video_processing.ProcessingResult) error {
+	for _, output := range result.Outputs {
+		if err := c.uploadOutputFile(ctx, output); err != nil {
+			return err
+		}
 
-		os.Remove(zipFilePath)
+		if output.Kind == video_processing.OutputPoster || output.Kind == video_processing.OutputPreview {
+			c.cacheOutputURL(ctx, job, output)
+		}
+	}
 
+	if len(result.Outputs) > 0 {
 		return nil
 	}
 
-	fileName := strings.TrimSuffix(job.FileName, filepath.Ext(job.FileName))
-	processedFileName := fmt.Sprintf("%s_processed.txt", fileName)
+	processedFileName := fmt.Sprintf("%s_processed.txt", video_processing.OutputBaseName(job))
 	objectName := fmt.Sprintf("%d/outputs/%s", job.UserID, processedFileName)
 
 	processedContent := fmt.Sprintf("Processed video content for %s\nFrames extracted: %d", job.FileName, result.FrameCount)
 
-	err := c.minioClient.UploadString(context.Background(), objectName, processedContent)
+	uploadCtx, cancel := context.WithTimeout(ctx, minUploadTimeout)
+	defer cancel()
+
+	err := c.store.UploadString(uploadCtx, objectName, processedContent)
 	if err != nil {
-		return fmt.Errorf("erro ao salvar vídeo processado: %w", err)
+		return storageError(fmt.Errorf("erro ao salvar vídeo processado: %w", err))
 	}
 
+	result.OutputKey = objectName
 	log.Printf("✅ Vídeo processado salvo: %s", objectName)
 	return nil
 }
+
+func (c *Consumer) uploadOutputFile(ctx context.Context, output video_processing.OutputFile) error {
+	file, err := os.Open(output.Path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de saída %s: %w", output.Path, err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("erro ao obter informações do arquivo: %w", err)
+	}
+
+	uploadCtx, cancel := context.WithTimeout(ctx, uploadTimeout(fileInfo.Size()))
+	defer cancel()
+
+	_, err = c.store.UploadFileWithContentType(uploadCtx, output.ObjectName, file, fileInfo.Size(), output.ContentType)
+	if err != nil {
+		return storageError(fmt.Errorf("erro ao salvar %s no MinIO: %w", output.Kind, err))
+	}
+
+	log.Printf("✅ Saída %s salva no MinIO: %s", output.Kind, output.ObjectName)
+
+	os.Remove(output.Path)
+
+	return nil
+}
+
+func uploadTimeout(size int64) time.Duration {
+	return minUploadTimeout + time.Duration(size/minUploadRate)*time.Second
+}
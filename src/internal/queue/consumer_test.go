@@ -0,0 +1,727 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"src/internal/api"
+	"src/internal/models"
+	"src/internal/services/video_processing"
+	"src/internal/services/video_processing/processingtest"
+	"src/internal/storage/storagetest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// blockingExtractor segura cada extração até release ser fechado ou o
+// contexto ser cancelado e registra o maior número de extrações simultâneas.
+type blockingExtractor struct {
+	*processingtest.FakeExtractor
+	release chan struct{}
+
+	mu          sync.Mutex
+	running     int
+	maxParallel int
+}
+
+func (b *blockingExtractor) ExtractFrames(ctx context.Context, videoPath, outputDir string, opts *models.ExtractionOptions, duration float64, onProgress video_processing.ProgressFunc) ([]string, []float64, error) {
+	b.mu.Lock()
+	b.running++
+	if b.running > b.maxParallel {
+		b.maxParallel = b.running
+	}
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.running--
+		b.mu.Unlock()
+	}()
+
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	return b.FakeExtractor.ExtractFrames(ctx, videoPath, outputDir, opts, duration, onProgress)
+}
+
+// fakeAcknowledger registra os acks e nacks feitos nas entregas.
+type fakeAcknowledger struct {
+	mu       sync.Mutex
+	acks     int
+	requeued int
+	dropped  int
+}
+
+func (f *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.acks++
+	return nil
+}
+
+func (f *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if requeue {
+		f.requeued++
+	} else {
+		f.dropped++
+	}
+	return nil
+}
+
+func (f *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return f.Nack(tag, false, requeue)
+}
+
+func (f *fakeAcknowledger) counts() (acks, requeued, dropped int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.acks, f.requeued, f.dropped
+}
+
+type publishedMessage struct {
+	exchange string
+	key      string
+	msg      amqp.Publishing
+}
+
+// fakePublisher registra as mensagens publicadas. As primeiras failures
+// publicações falham, simulando o RabbitMQ indisponível.
+type fakePublisher struct {
+	mu        sync.Mutex
+	failures  int
+	attempts  int
+	published []publishedMessage
+}
+
+func (f *fakePublisher) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.attempts <= f.failures {
+		return errors.New("RabbitMQ indisponível")
+	}
+	f.published = append(f.published, publishedMessage{exchange: exchange, key: key, msg: msg})
+	return nil
+}
+
+type fakeVideoAPI struct {
+	mu         sync.Mutex
+	statuses   []string
+	outputKeys []string
+	statusErr  error
+}
+
+func (f *fakeVideoAPI) CreateVideo(title, url string, userID uint, authToken string) (uint, error) {
+	return 1, nil
+}
+
+func (f *fakeVideoAPI) UpdateVideoStatus(videoID uint, status string, authToken string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statusErr != nil {
+		return f.statusErr
+	}
+	f.statuses = append(f.statuses, status)
+	return nil
+}
+
+func (f *fakeVideoAPI) UpdateVideoOutput(videoID uint, outputKey string, authToken string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.outputKeys = append(f.outputKeys, outputKey)
+	return nil
+}
+
+func (f *fakeVideoAPI) lastStatus() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.statuses) == 0 {
+		return ""
+	}
+	return f.statuses[len(f.statuses)-1]
+}
+
+func newTestConsumer(t *testing.T, extractor video_processing.FrameExtractor) (*Consumer, *storagetest.MemoryStore, *fakeVideoAPI) {
+	return newTestConsumerWithWorkers(t, extractor, 1)
+}
+
+// newTestConsumerWithWorkers cria um consumer sem RabbitMQ nem Redis, que
+// publica retries e DLQ num fakePublisher.
+func newTestConsumerWithWorkers(t *testing.T, extractor video_processing.FrameExtractor, workers int) (*Consumer, *storagetest.MemoryStore, *fakeVideoAPI) {
+	t.Helper()
+
+	processor, store := processingtest.NewProcessor(t, extractor)
+	videoAPI := &fakeVideoAPI{}
+
+	consumer := NewConsumer(nil, processor, store, nil, videoAPI, workers)
+	consumer.publisher = &fakePublisher{}
+	t.Cleanup(consumer.abortJobs)
+
+	return consumer, store, videoAPI
+}
+
+// failingExtractor cria um extractor cuja extração de frames falha com err.
+func failingExtractor(err error) *processingtest.FakeExtractor {
+	extractor := processingtest.NewFakeExtractor(0)
+	extractor.ExtractErr = err
+	return extractor
+}
+
+func outputObjects(store *storagetest.MemoryStore) []string {
+	var names []string
+	for _, name := range store.ObjectNames() {
+		if strings.HasPrefix(name, "1/outputs/") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestProcessAndSaveVideoUploadsOutputs(t *testing.T) {
+	consumer, store, videoAPI := newTestConsumer(t, processingtest.NewFakeExtractor(2))
+
+	result, err := consumer.processAndSaveVideo(context.Background(), processingtest.NewJob(), 1)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if result.FrameCount != 2 {
+		t.Errorf("FrameCount = %d, esperado 2", result.FrameCount)
+	}
+
+	objects := outputObjects(store)
+	if len(objects) != 1 || !strings.HasSuffix(objects[0], ".zip") {
+		t.Fatalf("objetos de saída inesperados: %v", objects)
+	}
+	if object, _ := store.Get(objects[0]); object.ContentType != "application/zip" {
+		t.Errorf("ContentType = %s, esperado application/zip", object.ContentType)
+	}
+	if _, err := os.Stat(result.Outputs[0].Path); !os.IsNotExist(err) {
+		t.Errorf("arquivo local %s deveria ter sido removido após o upload", result.Outputs[0].Path)
+	}
+	if videoAPI.lastStatus() != models.StatusProcessing {
+		t.Errorf("último status enviado à API = %s, esperado %s", videoAPI.lastStatus(), models.StatusProcessing)
+	}
+}
+
+func TestProcessAndSaveVideoFFmpegFailure(t *testing.T) {
+	consumer, store, _ := newTestConsumer(t, failingExtractor(errors.New("exit status 1")))
+
+	result, err := consumer.processAndSaveVideo(context.Background(), processingtest.NewJob(), 1)
+	if err == nil {
+		t.Fatal("erro esperado quando o ffmpeg falha")
+	}
+	if result.Status != models.StatusFailed {
+		t.Errorf("status = %s, esperado %s", result.Status, models.StatusFailed)
+	}
+	if objects := outputObjects(store); len(objects) != 0 {
+		t.Errorf("nenhum objeto de saída esperado, obtidos %v", objects)
+	}
+}
+
+func TestProcessAndSaveVideoEmptyFrames(t *testing.T) {
+	consumer, store, _ := newTestConsumer(t, processingtest.NewFakeExtractor(0))
+
+	_, err := consumer.processAndSaveVideo(context.Background(), processingtest.NewJob(), 1)
+	if err == nil || !strings.Contains(err.Error(), "nenhum frame") {
+		t.Fatalf("erro esperado sobre frames vazios, obtido %v", err)
+	}
+	if objects := outputObjects(store); len(objects) != 0 {
+		t.Errorf("nenhum objeto de saída esperado, obtidos %v", objects)
+	}
+}
+
+func TestProcessAndSaveVideoUploadFailure(t *testing.T) {
+	consumer, store, _ := newTestConsumer(t, processingtest.NewFakeExtractor(2))
+	store.UploadErr = errors.New("minio indisponível")
+
+	_, err := consumer.processAndSaveVideo(context.Background(), processingtest.NewJob(), 1)
+	if err == nil || !strings.Contains(err.Error(), "minio indisponível") {
+		t.Fatalf("erro de upload esperado, obtido %v", err)
+	}
+	if class := video_processing.ErrorClassOf(err); class != video_processing.ErrorStorageUnavailable {
+		t.Errorf("classe = %s, esperado %s", class, video_processing.ErrorStorageUnavailable)
+	}
+}
+
+// deadlineStore registra o prazo do contexto de cada upload.
+type deadlineStore struct {
+	*storagetest.MemoryStore
+	deadlines []time.Duration
+}
+
+func (d *deadlineStore) UploadFileWithContentType(ctx context.Context, objectName string, file io.Reader, size int64, contentType string) (string, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		d.deadlines = append(d.deadlines, time.Until(deadline))
+	}
+	return d.MemoryStore.UploadFileWithContentType(ctx, objectName, file, size, contentType)
+}
+
+func TestProcessAndSaveVideoBoundsUploads(t *testing.T) {
+	consumer, store, _ := newTestConsumer(t, processingtest.NewFakeExtractor(2))
+	uploads := &deadlineStore{MemoryStore: store}
+	consumer.store = uploads
+
+	if _, err := consumer.processAndSaveVideo(context.Background(), processingtest.NewJob(), 1); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(uploads.deadlines) != 1 {
+		t.Fatalf("uploads com prazo = %d, esperado 1", len(uploads.deadlines))
+	}
+	if remaining := uploads.deadlines[0]; remaining <= 0 || remaining > minUploadTimeout {
+		t.Errorf("prazo do upload = %s, esperado até %s para uma saída pequena", remaining, minUploadTimeout)
+	}
+}
+
+func TestUploadTimeoutScalesWithSize(t *testing.T) {
+	if got := uploadTimeout(0); got != minUploadTimeout {
+		t.Errorf("uploadTimeout(0) = %s, esperado %s", got, minUploadTimeout)
+	}
+	if got, expected := uploadTimeout(600*minUploadRate), minUploadTimeout+10*time.Minute; got != expected {
+		t.Errorf("uploadTimeout(600 MiB) = %s, esperado %s", got, expected)
+	}
+}
+
+func TestProcessAndSaveVideoAPIFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		statusErr error
+		want      video_processing.ErrorClass
+	}{
+		{"vídeo removido", &api.StatusError{StatusCode: http.StatusNotFound}, video_processing.ErrorInvalidInput},
+		{"API fora do ar", &api.StatusError{StatusCode: http.StatusServiceUnavailable}, video_processing.ErrorAPIUnavailable},
+		{"erro de conexão", errors.New("connection refused"), video_processing.ErrorAPIUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consumer, store, videoAPI := newTestConsumer(t, processingtest.NewFakeExtractor(1))
+			videoAPI.statusErr = tt.statusErr
+
+			_, err := consumer.processAndSaveVideo(context.Background(), processingtest.NewJob(), 1)
+			if class := video_processing.ErrorClassOf(err); class != tt.want {
+				t.Errorf("classe = %s, esperado %s (erro: %v)", class, tt.want, err)
+			}
+			if objects := outputObjects(store); len(objects) != 0 {
+				t.Errorf("o vídeo não deveria ser processado sem a API, obtidos %v", objects)
+			}
+		})
+	}
+}
+
+func TestProcessAndSaveVideoIgnoresRejectedToken(t *testing.T) {
+	for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		t.Run(http.StatusText(code), func(t *testing.T) {
+			consumer, store, videoAPI := newTestConsumer(t, processingtest.NewFakeExtractor(1))
+			videoAPI.statusErr = &api.StatusError{StatusCode: code}
+
+			result, err := consumer.processAndSaveVideo(context.Background(), processingtest.NewJob(), 1)
+			if err != nil {
+				t.Fatalf("token expirado não deveria falhar o job: %v", err)
+			}
+			if result.Status != models.StatusCompleted || len(outputObjects(store)) == 0 {
+				t.Errorf("o vídeo deveria ser processado e salvo, status = %s", result.Status)
+			}
+		})
+	}
+}
+
+func TestHandleMessageMarksVideoCompleted(t *testing.T) {
+	consumer, _, videoAPI := newTestConsumer(t, processingtest.NewFakeExtractor(1))
+
+	body, err := json.Marshal(processingtest.NewJob())
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+
+	consumer.handleMessage(consumer.jobsCtx, amqp.Delivery{Body: body})
+
+	if videoAPI.lastStatus() != models.StatusCompleted {
+		t.Errorf("último status enviado à API = %s, esperado %s", videoAPI.lastStatus(), models.StatusCompleted)
+	}
+	expectedKey := "1/outputs/video_42_job_test.zip"
+	if len(videoAPI.outputKeys) != 1 || videoAPI.outputKeys[0] != expectedKey {
+		t.Errorf("chaves de saída enviadas à API = %v, esperado [%s]", videoAPI.outputKeys, expectedKey)
+	}
+}
+
+func TestRunWorkersRespectsPoolSize(t *testing.T) {
+	extractor := &blockingExtractor{FakeExtractor: processingtest.NewFakeExtractor(1), release: make(chan struct{})}
+	consumer, _, _ := newTestConsumerWithWorkers(t, extractor, 2)
+
+	body, err := json.Marshal(processingtest.NewJob())
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+
+	msgs := make(chan amqp.Delivery, 5)
+	for i := 0; i < 5; i++ {
+		msgs <- amqp.Delivery{Body: body}
+	}
+	close(msgs)
+
+	done := make(chan struct{})
+	go func() {
+		consumer.runWorkers(context.Background(), consumer.jobsCtx, msgs)
+		consumer.workersWG.Wait()
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for consumer.Stats().InFlight < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("workers não iniciaram: %+v", consumer.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if stats := consumer.Stats(); stats.InFlight != 2 || stats.Idle != 0 {
+		t.Errorf("stats = %+v, esperado 2 em andamento e 0 ociosos", stats)
+	}
+
+	close(extractor.release)
+	<-done
+
+	if extractor.maxParallel > 2 {
+		t.Errorf("%d extrações simultâneas, limite era 2", extractor.maxParallel)
+	}
+	if stats := consumer.Stats(); stats.InFlight != 0 || stats.Idle != 2 {
+		t.Errorf("stats = %+v, esperado 0 em andamento e 2 ociosos", stats)
+	}
+}
+
+func startBlockedWorker(t *testing.T, extractor *blockingExtractor) (*Consumer, *fakeVideoAPI, *fakeAcknowledger, context.CancelFunc) {
+	t.Helper()
+
+	consumer, _, videoAPI := newTestConsumer(t, extractor)
+
+	body, err := json.Marshal(processingtest.NewJob())
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+
+	ack := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 1)
+	msgs <- amqp.Delivery{Acknowledger: ack, Body: body}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go consumer.runWorkers(ctx, consumer.jobsCtx, msgs)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for consumer.Stats().InFlight == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker não iniciou o job")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return consumer, videoAPI, ack, cancel
+}
+
+func TestShutdownWaitsForInFlightJobs(t *testing.T) {
+	extractor := &blockingExtractor{FakeExtractor: processingtest.NewFakeExtractor(1), release: make(chan struct{})}
+	consumer, videoAPI, ack, cancel := startBlockedWorker(t, extractor)
+	cancel()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(extractor.release)
+	}()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelShutdown()
+
+	if err := consumer.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("erro inesperado no shutdown: %v", err)
+	}
+	if acks, requeued, _ := ack.counts(); acks != 1 || requeued != 0 {
+		t.Errorf("acks = %d, requeued = %d, esperado o job concluído e confirmado", acks, requeued)
+	}
+	if videoAPI.lastStatus() != models.StatusCompleted {
+		t.Errorf("último status enviado à API = %s, esperado %s", videoAPI.lastStatus(), models.StatusCompleted)
+	}
+}
+
+func TestShutdownRequeuesJobsAfterDeadline(t *testing.T) {
+	extractor := &blockingExtractor{FakeExtractor: processingtest.NewFakeExtractor(1), release: make(chan struct{})}
+	consumer, videoAPI, ack, cancel := startBlockedWorker(t, extractor)
+	cancel()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelShutdown()
+
+	if err := consumer.Shutdown(shutdownCtx); err == nil {
+		t.Fatal("erro esperado quando o prazo de encerramento expira")
+	}
+	if acks, requeued, dropped := ack.counts(); requeued != 1 || acks != 0 || dropped != 0 {
+		t.Errorf("acks = %d, requeued = %d, dropped = %d, esperado o job devolvido à fila", acks, requeued, dropped)
+	}
+	if videoAPI.lastStatus() == models.StatusFailed {
+		t.Error("job interrompido pelo shutdown não deveria ser marcado como falho")
+	}
+}
+
+func TestRunWorkersAbandonsJobsOfClosedChannel(t *testing.T) {
+	extractor := &blockingExtractor{FakeExtractor: processingtest.NewFakeExtractor(1), release: make(chan struct{})}
+	consumer, _, videoAPI := newTestConsumer(t, extractor)
+	publisher := consumer.publisher.(*fakePublisher)
+
+	body, err := json.Marshal(processingtest.NewJob())
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+
+	ack := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 1)
+	msgs <- amqp.Delivery{Acknowledger: ack, Body: body}
+
+	// deliveriesCtx é cancelado por consume quando o canal fecha.
+	deliveriesCtx, abandon := context.WithCancel(consumer.jobsCtx)
+	defer abandon()
+
+	returned := make(chan struct{})
+	go func() {
+		consumer.runWorkers(context.Background(), deliveriesCtx, msgs)
+		close(returned)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for consumer.Stats().InFlight == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("worker não iniciou o job")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	abandon()
+	select {
+	case <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("runWorkers deveria retornar com o canal fechado")
+	}
+	consumer.workersWG.Wait()
+
+	if acks, requeued, dropped := ack.counts(); acks != 0 || requeued != 0 || dropped != 0 {
+		t.Errorf("acks = %d, requeued = %d, dropped = %d, esperado nenhuma confirmação no canal fechado", acks, requeued, dropped)
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("mensagens publicadas = %d, esperado nenhuma para o job abandonado", len(publisher.published))
+	}
+	if videoAPI.lastStatus() == models.StatusFailed {
+		t.Error("job abandonado não deveria ser marcado como falho")
+	}
+}
+
+func retryDeath(count int64) amqp.Table {
+	return amqp.Table{"x-death": []interface{}{
+		amqp.Table{"queue": retryQueueName(1), "reason": "expired", "count": count},
+	}}
+}
+
+func TestDeliveryAttempt(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers amqp.Table
+		want    int
+	}{
+		{"sem cabeçalhos", nil, 1},
+		{"uma passagem", retryDeath(1), 2},
+		{"duas filas de retry", amqp.Table{"x-death": []interface{}{
+			amqp.Table{"queue": retryQueueName(2), "count": int64(1)},
+			amqp.Table{"queue": retryQueueName(1), "count": int64(1)},
+		}}, 3},
+		{"ignora outras filas", amqp.Table{"x-death": []interface{}{
+			amqp.Table{"queue": "outra_fila", "count": int64(5)},
+		}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveryAttempt(tt.headers); got != tt.want {
+				t.Errorf("deliveryAttempt = %d, esperado %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleMessageSchedulesRetry(t *testing.T) {
+	consumer, _, videoAPI := newTestConsumer(t, failingExtractor(errors.New("exit status 1")))
+	publisher := consumer.publisher.(*fakePublisher)
+
+	body, err := json.Marshal(processingtest.NewJob())
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+	ack := &fakeAcknowledger{}
+
+	consumer.handleMessage(consumer.jobsCtx, amqp.Delivery{Acknowledger: ack, Body: body})
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("mensagens publicadas = %d, esperado 1", len(publisher.published))
+	}
+	if published := publisher.published[0]; published.exchange != models.RetryExchange || published.key != retryRoutingKey(1) {
+		t.Errorf("publicado em %s/%s, esperado %s/%s", published.exchange, published.key, models.RetryExchange, retryRoutingKey(1))
+	}
+	if acks, requeued, dropped := ack.counts(); acks != 1 || requeued != 0 || dropped != 0 {
+		t.Errorf("acks = %d, requeued = %d, dropped = %d, esperado apenas o ack", acks, requeued, dropped)
+	}
+	if videoAPI.lastStatus() == models.StatusFailed {
+		t.Error("job com novas tentativas não deveria ser marcado como falho")
+	}
+}
+
+func TestHandleMessageBacksOffWhenRetryPublishFails(t *testing.T) {
+	consumer, _, _ := newTestConsumer(t, failingExtractor(errors.New("exit status 1")))
+	publisher := consumer.publisher.(*fakePublisher)
+	publisher.failures = 2
+	delays := &delayRecorder{}
+	consumer.after = delays.after
+
+	body, err := json.Marshal(processingtest.NewJob())
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+	ack := &fakeAcknowledger{}
+
+	consumer.handleMessage(consumer.jobsCtx, amqp.Delivery{Acknowledger: ack, Body: body})
+
+	expected := []time.Duration{time.Second, 2 * time.Second}
+	if got := delays.recorded(); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("atrasos entre as publicações = %v, esperado %v", got, expected)
+	}
+	if len(publisher.published) != 1 || publisher.published[0].exchange != models.RetryExchange {
+		t.Fatalf("mensagens publicadas = %+v, esperado uma na fila de retry", publisher.published)
+	}
+	if acks, requeued, dropped := ack.counts(); acks != 1 || requeued != 0 || dropped != 0 {
+		t.Errorf("acks = %d, requeued = %d, dropped = %d, esperado apenas o ack", acks, requeued, dropped)
+	}
+}
+
+func TestHandleMessageRequeuesAfterPublishAttempts(t *testing.T) {
+	consumer, _, _ := newTestConsumer(t, processingtest.NewFakeExtractor(1))
+	publisher := consumer.publisher.(*fakePublisher)
+	publisher.failures = publishAttempts
+	delays := &delayRecorder{}
+	consumer.after = delays.after
+
+	ack := &fakeAcknowledger{}
+
+	consumer.handleMessage(consumer.jobsCtx, amqp.Delivery{Acknowledger: ack, Body: []byte("{inválido")})
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	if got := delays.recorded(); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("atrasos entre as publicações = %v, esperado %v", got, expected)
+	}
+	if publisher.attempts != publishAttempts {
+		t.Errorf("publicações tentadas = %d, esperado %d", publisher.attempts, publishAttempts)
+	}
+	if acks, requeued, dropped := ack.counts(); acks != 0 || requeued != 1 || dropped != 0 {
+		t.Errorf("acks = %d, requeued = %d, dropped = %d, esperado apenas a devolução à fila", acks, requeued, dropped)
+	}
+}
+
+func TestHandleMessageStopsPublishBackoffWhenCanceled(t *testing.T) {
+	consumer, _, _ := newTestConsumer(t, processingtest.NewFakeExtractor(1))
+	publisher := consumer.publisher.(*fakePublisher)
+	publisher.failures = publishAttempts
+	consumer.after = func(time.Duration) <-chan time.Time { return nil }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ack := &fakeAcknowledger{}
+
+	consumer.handleMessage(ctx, amqp.Delivery{Acknowledger: ack, Body: []byte("{inválido")})
+
+	if publisher.attempts != 1 {
+		t.Errorf("publicações tentadas = %d, esperado 1", publisher.attempts)
+	}
+	if _, requeued, _ := ack.counts(); requeued != 1 {
+		t.Errorf("requeued = %d, esperado 1", requeued)
+	}
+}
+
+func TestHandleMessageDeadLettersAfterMaxRetries(t *testing.T) {
+	consumer, _, videoAPI := newTestConsumer(t, failingExtractor(errors.New("exit status 1")))
+	publisher := consumer.publisher.(*fakePublisher)
+
+	body, err := json.Marshal(processingtest.NewJob())
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+	ack := &fakeAcknowledger{}
+
+	consumer.handleMessage(consumer.jobsCtx, amqp.Delivery{Acknowledger: ack, Headers: retryDeath(maxRetries - 1), Body: body})
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("mensagens publicadas = %d, esperado 1", len(publisher.published))
+	}
+	published := publisher.published[0]
+	if published.exchange != models.DeadLetterExchange {
+		t.Errorf("publicado em %s, esperado %s", published.exchange, models.DeadLetterExchange)
+	}
+	if reason, _ := published.msg.Headers[headerFailureReason].(string); !strings.Contains(reason, "exit status 1") {
+		t.Errorf("motivo da falha = %q, esperado o erro do ffmpeg", reason)
+	}
+
+	var failed models.VideoProcessingJob
+	if err := json.Unmarshal(published.msg.Body, &failed); err != nil {
+		t.Fatalf("erro ao ler job da DLQ: %v", err)
+	}
+	if failed.Status != models.StatusFailed || failed.Attempts != maxRetries || !strings.Contains(failed.Error, "exit status 1") {
+		t.Errorf("job na DLQ inesperado: status=%s attempts=%d error=%q", failed.Status, failed.Attempts, failed.Error)
+	}
+
+	if acks, _, _ := ack.counts(); acks != 1 {
+		t.Errorf("acks = %d, esperado 1", acks)
+	}
+	if videoAPI.lastStatus() != models.StatusFailed {
+		t.Errorf("último status enviado à API = %s, esperado %s", videoAPI.lastStatus(), models.StatusFailed)
+	}
+}
+
+func TestHandleMessageDeadLettersPermanentFailure(t *testing.T) {
+	decodeErr := video_processing.NewProcessingError(video_processing.ErrorDecodeFailure, errors.New("invalid data found when processing input"))
+	consumer, _, videoAPI := newTestConsumer(t, failingExtractor(decodeErr))
+	publisher := consumer.publisher.(*fakePublisher)
+
+	body, err := json.Marshal(processingtest.NewJob())
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+	ack := &fakeAcknowledger{}
+
+	consumer.handleMessage(consumer.jobsCtx, amqp.Delivery{Acknowledger: ack, Body: body})
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("mensagens publicadas = %d, esperado 1", len(publisher.published))
+	}
+	published := publisher.published[0]
+	if published.exchange != models.DeadLetterExchange {
+		t.Errorf("falha permanente publicada em %s, esperado %s já na primeira tentativa", published.exchange, models.DeadLetterExchange)
+	}
+	if class, _ := published.msg.Headers[headerErrorClass].(string); class != string(video_processing.ErrorDecodeFailure) {
+		t.Errorf("%s = %q, esperado %s", headerErrorClass, class, video_processing.ErrorDecodeFailure)
+	}
+
+	var failed models.VideoProcessingJob
+	if err := json.Unmarshal(published.msg.Body, &failed); err != nil {
+		t.Fatalf("erro ao ler job da DLQ: %v", err)
+	}
+	if failed.ErrorClass != string(video_processing.ErrorDecodeFailure) || failed.Attempts != 1 {
+		t.Errorf("job na DLQ inesperado: error_class=%s attempts=%d", failed.ErrorClass, failed.Attempts)
+	}
+	if videoAPI.lastStatus() != models.StatusFailed {
+		t.Errorf("último status enviado à API = %s, esperado %s", videoAPI.lastStatus(), models.StatusFailed)
+	}
+}
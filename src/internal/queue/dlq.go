@@ -0,0 +1,212 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"src/internal/models"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetterJob é uma mensagem estacionada na DLQ. Mensagens que não são
+// jobs válidos trazem apenas Body e Reason.
+type DeadLetterJob struct {
+	Job        *models.VideoProcessingJob `json:"job,omitempty"`
+	Body       string                     `json:"body,omitempty"`
+	Reason     string                     `json:"reason"`
+	ErrorClass string                     `json:"error_class,omitempty"`
+	FailedAt   string                     `json:"failed_at,omitempty"`
+	Attempts   int                        `json:"attempts"`
+}
+
+// JobPublisher publica jobs na fila de processamento.
+type JobPublisher interface {
+	PublishVideoProcessingJob(job *models.VideoProcessingJob) error
+}
+
+// deadLetterChannel é o subconjunto de *amqp.Channel usado para inspecionar
+// a DLQ.
+type deadLetterChannel interface {
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	QueuePurge(name string, noWait bool) (int, error)
+	Close() error
+}
+
+// DeadLetterQueue lista, reenvia e remove jobs da DLQ. Cada operação usa um
+// canal próprio para não interferir nas entregas do consumer.
+type DeadLetterQueue struct {
+	openChannel func() (deadLetterChannel, error)
+	publisher   JobPublisher
+	mu          sync.Mutex
+}
+
+func NewDeadLetterQueue(client *RabbitMQClient, publisher JobPublisher) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		openChannel: func() (deadLetterChannel, error) {
+			return client.OpenChannel()
+		},
+		publisher: publisher,
+	}
+}
+
+// List devolve até limit jobs da DLQ (todos, se limit <= 0) sem removê-los.
+// O token de autenticação dos jobs não é exposto.
+func (d *DeadLetterQueue) List(limit int) ([]DeadLetterJob, error) {
+	var entries []DeadLetterJob
+
+	err := d.scan(limit, func(entry DeadLetterJob) (bool, error) {
+		if entry.Job != nil {
+			job := *entry.Job
+			job.AuthToken = ""
+			entry.Job = &job
+		}
+		entries = append(entries, entry)
+		return false, nil
+	})
+
+	return entries, err
+}
+
+// Replay publica novamente na fila de processamento o job com o ID
+// informado, ou todos os jobs válidos se jobID for vazio, e os remove da DLQ.
+func (d *DeadLetterQueue) Replay(jobID string) ([]models.VideoProcessingJob, error) {
+	var replayed []models.VideoProcessingJob
+
+	err := d.scan(0, func(entry DeadLetterJob) (bool, error) {
+		if entry.Job == nil || (jobID != "" && entry.Job.ID != jobID) {
+			return false, nil
+		}
+
+		job := *entry.Job
+		job.Status = models.StatusPending
+		job.Attempts = 0
+		job.Error = ""
+		job.ErrorClass = ""
+		job.UpdatedAt = time.Now()
+
+		if err := d.publisher.PublishVideoProcessingJob(&job); err != nil {
+			return false, fmt.Errorf("erro ao reenviar job %s: %w", job.ID, err)
+		}
+
+		log.Printf("🔁 Job reenviado da DLQ: JobID=%s, VideoID=%d", job.ID, job.VideoID)
+		replayed = append(replayed, job)
+		return true, nil
+	})
+
+	return replayed, err
+}
+
+// Remove descarta da DLQ o job com o ID informado.
+func (d *DeadLetterQueue) Remove(jobID string) (int, error) {
+	removed := 0
+
+	err := d.scan(0, func(entry DeadLetterJob) (bool, error) {
+		if entry.Job == nil || entry.Job.ID != jobID {
+			return false, nil
+		}
+		removed++
+		return true, nil
+	})
+
+	return removed, err
+}
+
+// Purge descarta todas as mensagens da DLQ.
+func (d *DeadLetterQueue) Purge() (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	channel, err := d.openChannel()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao abrir canal: %w", err)
+	}
+	defer channel.Close()
+
+	purged, err := channel.QueuePurge(models.DeadLetterQueue, false)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao limpar DLQ: %w", err)
+	}
+
+	log.Printf("🧹 %d jobs removidos da DLQ", purged)
+	return purged, nil
+}
+
+// scan percorre a DLQ mantendo cada entrega pendente até o fim da varredura,
+// para que nenhuma mensagem seja lida duas vezes. As entregas para as quais
+// visit devolve true são removidas (ack); as demais voltam para a DLQ.
+func (d *DeadLetterQueue) scan(limit int, visit func(DeadLetterJob) (bool, error)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	channel, err := d.openChannel()
+	if err != nil {
+		return fmt.Errorf("erro ao abrir canal: %w", err)
+	}
+	defer channel.Close()
+
+	var kept []amqp.Delivery
+	defer func() {
+		for _, msg := range kept {
+			if err := msg.Nack(false, true); err != nil {
+				log.Printf("Erro ao devolver mensagem à DLQ: %v", err)
+			}
+		}
+	}()
+
+	var visitErr error
+	for count := 0; limit <= 0 || count < limit; count++ {
+		msg, ok, err := channel.Get(models.DeadLetterQueue, false)
+		if err != nil {
+			return fmt.Errorf("erro ao ler DLQ: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		remove, err := visit(parseDeadLetter(msg))
+		if err != nil && visitErr == nil {
+			visitErr = err
+		}
+
+		if !remove {
+			kept = append(kept, msg)
+			continue
+		}
+		if err := msg.Ack(false); err != nil {
+			return fmt.Errorf("erro ao remover mensagem da DLQ: %w", err)
+		}
+	}
+
+	return visitErr
+}
+
+func parseDeadLetter(msg amqp.Delivery) DeadLetterJob {
+	entry := DeadLetterJob{}
+	entry.Reason, _ = msg.Headers[headerFailureReason].(string)
+	entry.ErrorClass, _ = msg.Headers[headerErrorClass].(string)
+	entry.FailedAt, _ = msg.Headers[headerFailedAt].(string)
+	if attempts, ok := msg.Headers[headerAttempts].(int32); ok {
+		entry.Attempts = int(attempts)
+	}
+
+	var job models.VideoProcessingJob
+	if err := json.Unmarshal(msg.Body, &job); err != nil {
+		entry.Body = string(msg.Body)
+		return entry
+	}
+
+	entry.Job = &job
+	if entry.Reason == "" {
+		entry.Reason = job.Error
+	}
+	if entry.ErrorClass == "" {
+		entry.ErrorClass = job.ErrorClass
+	}
+	if entry.Attempts == 0 {
+		entry.Attempts = job.Attempts
+	}
+	return entry
+}
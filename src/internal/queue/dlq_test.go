@@ -0,0 +1,185 @@
+package queue
+
+import (
+	"encoding/json"
+	"src/internal/models"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeDLQChannel simula a DLQ: Get entrega as mensagens em ordem e apenas
+// as confirmadas com Ack deixam a fila.
+type fakeDLQChannel struct {
+	messages []amqp.Delivery
+	removed  map[uint64]bool
+	next     int
+}
+
+func newFakeDLQChannel(t *testing.T, bodies ...[]byte) *fakeDLQChannel {
+	t.Helper()
+
+	channel := &fakeDLQChannel{removed: make(map[uint64]bool)}
+	for i, body := range bodies {
+		channel.messages = append(channel.messages, amqp.Delivery{
+			Acknowledger: channel,
+			DeliveryTag:  uint64(i + 1),
+			Headers:      amqp.Table{headerFailureReason: "falha no ffmpeg", headerErrorClass: "decode_failure", headerAttempts: int32(maxRetries)},
+			Body:         body,
+		})
+	}
+	return channel
+}
+
+func (f *fakeDLQChannel) Get(queue string, autoAck bool) (amqp.Delivery, bool, error) {
+	if f.next >= len(f.messages) {
+		return amqp.Delivery{}, false, nil
+	}
+	msg := f.messages[f.next]
+	f.next++
+	return msg, true, nil
+}
+
+func (f *fakeDLQChannel) QueuePurge(name string, noWait bool) (int, error) {
+	purged := len(f.remaining())
+	for _, msg := range f.messages {
+		f.removed[msg.DeliveryTag] = true
+	}
+	return purged, nil
+}
+
+func (f *fakeDLQChannel) Close() error {
+	f.next = 0
+	return nil
+}
+
+func (f *fakeDLQChannel) Ack(tag uint64, multiple bool) error {
+	f.removed[tag] = true
+	return nil
+}
+
+func (f *fakeDLQChannel) Nack(tag uint64, multiple, requeue bool) error {
+	if !requeue {
+		f.removed[tag] = true
+	}
+	return nil
+}
+
+func (f *fakeDLQChannel) Reject(tag uint64, requeue bool) error {
+	return f.Nack(tag, false, requeue)
+}
+
+func (f *fakeDLQChannel) remaining() []amqp.Delivery {
+	var remaining []amqp.Delivery
+	for _, msg := range f.messages {
+		if !f.removed[msg.DeliveryTag] {
+			remaining = append(remaining, msg)
+		}
+	}
+	return remaining
+}
+
+type fakeJobPublisher struct {
+	jobs []models.VideoProcessingJob
+	err  error
+}
+
+func (f *fakeJobPublisher) PublishVideoProcessingJob(job *models.VideoProcessingJob) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.jobs = append(f.jobs, *job)
+	return nil
+}
+
+func failedJobBody(t *testing.T, id string) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(&models.VideoProcessingJob{
+		ID:        id,
+		VideoID:   42,
+		UserID:    1,
+		Status:    models.StatusFailed,
+		AuthToken: "Bearer segredo",
+		Attempts:  maxRetries,
+		Error:     "falha no ffmpeg",
+	})
+	if err != nil {
+		t.Fatalf("erro ao serializar job: %v", err)
+	}
+	return body
+}
+
+func newTestDeadLetterQueue(channel *fakeDLQChannel) (*DeadLetterQueue, *fakeJobPublisher) {
+	publisher := &fakeJobPublisher{}
+	return &DeadLetterQueue{
+		openChannel: func() (deadLetterChannel, error) { return channel, nil },
+		publisher:   publisher,
+	}, publisher
+}
+
+func TestDeadLetterQueueListKeepsMessages(t *testing.T) {
+	channel := newFakeDLQChannel(t, failedJobBody(t, "job_1"), []byte("não é json"))
+	dlq, _ := newTestDeadLetterQueue(channel)
+
+	entries, err := dlq.List(0)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entradas = %d, esperado 2", len(entries))
+	}
+	if entries[0].Job == nil || entries[0].Job.ID != "job_1" || entries[0].Reason != "falha no ffmpeg" || entries[0].ErrorClass != "decode_failure" || entries[0].Attempts != maxRetries {
+		t.Errorf("entrada inesperada: %+v", entries[0])
+	}
+	if entries[0].Job.AuthToken != "" {
+		t.Error("token de autenticação não deveria ser exposto na listagem")
+	}
+	if entries[1].Job != nil || entries[1].Body != "não é json" {
+		t.Errorf("mensagem inválida deveria trazer apenas o corpo: %+v", entries[1])
+	}
+	if remaining := channel.remaining(); len(remaining) != 2 {
+		t.Errorf("mensagens na DLQ após listar = %d, esperado 2", len(remaining))
+	}
+}
+
+func TestDeadLetterQueueReplayOne(t *testing.T) {
+	channel := newFakeDLQChannel(t, failedJobBody(t, "job_1"), failedJobBody(t, "job_2"))
+	dlq, publisher := newTestDeadLetterQueue(channel)
+
+	replayed, err := dlq.Replay("job_2")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(replayed) != 1 || len(publisher.jobs) != 1 {
+		t.Fatalf("reenviados = %d, publicados = %d, esperado 1", len(replayed), len(publisher.jobs))
+	}
+
+	job := publisher.jobs[0]
+	if job.ID != "job_2" || job.Status != models.StatusPending || job.Error != "" || job.Attempts != 0 {
+		t.Errorf("job reenviado inesperado: %+v", job)
+	}
+	if job.AuthToken == "" {
+		t.Error("job reenviado deveria manter o token para atualizar a API")
+	}
+
+	remaining := channel.remaining()
+	if len(remaining) != 1 || remaining[0].DeliveryTag != 1 {
+		t.Errorf("apenas job_1 deveria continuar na DLQ, restaram %d mensagens", len(remaining))
+	}
+}
+
+func TestDeadLetterQueueReplayAllSkipsInvalidMessages(t *testing.T) {
+	channel := newFakeDLQChannel(t, failedJobBody(t, "job_1"), []byte("não é json"), failedJobBody(t, "job_2"))
+	dlq, publisher := newTestDeadLetterQueue(channel)
+
+	if _, err := dlq.Replay(""); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(publisher.jobs) != 2 {
+		t.Errorf("publicados = %d, esperado 2", len(publisher.jobs))
+	}
+	if remaining := channel.remaining(); len(remaining) != 1 || string(remaining[0].Body) != "não é json" {
+		t.Errorf("apenas a mensagem inválida deveria continuar na DLQ, restaram %d", len(remaining))
+	}
+}
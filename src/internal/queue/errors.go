@@ -0,0 +1,35 @@
+package queue
+
+import (
+	"errors"
+	"net/http"
+	"src/internal/api"
+	"src/internal/services/video_processing"
+)
+
+// apiError classifica uma falha da API principal. Vídeo inexistente não muda
+// numa nova tentativa; as demais falhas são tratadas como indisponibilidade
+// da API.
+func apiError(err error) error {
+	var statusErr *api.StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return video_processing.NewProcessingError(video_processing.ErrorInvalidInput, err)
+	}
+	return video_processing.NewProcessingError(video_processing.ErrorAPIUnavailable, err)
+}
+
+// isAuthError indica se a API recusou o token do job. Isso não diz nada
+// sobre o vídeo: jobs reenviados da DLQ carregam o token do upload, que pode
+// já ter expirado.
+func isAuthError(err error) bool {
+	var statusErr *api.StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	return statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden
+}
+
+// storageError classifica uma falha ao gravar as saídas no storage.
+func storageError(err error) error {
+	return video_processing.NewProcessingError(video_processing.ErrorStorageUnavailable, err)
+}
@@ -0,0 +1,131 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"src/internal/cache"
+	"src/internal/models"
+	"time"
+)
+
+const (
+	outboxRelayInterval = 10 * time.Second
+
+	// Idade mínima de uma entrada para o relay reenviá-la, para não disputar
+	// com a publicação feita pelo próprio Enqueue.
+	outboxRelayMinAge = 30 * time.Second
+)
+
+// outboxStore é o subconjunto do RedisClient usado pelo outbox.
+type outboxStore interface {
+	SaveOutboxEntry(ctx context.Context, entry *cache.OutboxEntry) error
+	DeleteOutboxEntry(ctx context.Context, jobID string) error
+	ListOutboxEntries(ctx context.Context) ([]cache.OutboxEntry, error)
+}
+
+// Outbox guarda no Redis os jobs aceitos até que o RabbitMQ confirme a
+// publicação. Jobs que não puderam ser publicados na hora são reenviados
+// pelo relay (Run), de modo que todo upload aceito gera um job na fila.
+type Outbox struct {
+	store     outboxStore
+	publisher JobPublisher
+}
+
+func NewOutbox(redisClient *cache.RedisClient, publisher JobPublisher) *Outbox {
+	return &Outbox{
+		store:     redisClient,
+		publisher: publisher,
+	}
+}
+
+// Enqueue registra o job no outbox e tenta publicá-lo em seguida. Só
+// devolve erro se o job não puder ser publicado nem guardado para uma nova
+// tentativa. O cancelamento de ctx (como a desconexão do cliente HTTP) não
+// interrompe o registro.
+func (o *Outbox) Enqueue(ctx context.Context, job *models.VideoProcessingJob) error {
+	ctx = context.WithoutCancel(ctx)
+	entry := &cache.OutboxEntry{Job: *job, EnqueuedAt: time.Now()}
+
+	saveErr := o.store.SaveOutboxEntry(ctx, entry)
+	if saveErr != nil {
+		log.Printf("Erro ao salvar job %s no outbox: %v", job.ID, saveErr)
+	}
+
+	if err := o.publisher.PublishVideoProcessingJob(job); err != nil {
+		if saveErr != nil {
+			return fmt.Errorf("erro ao publicar job: %w", err)
+		}
+		log.Printf("⚠️ Job %s mantido no outbox para nova tentativa: %v", job.ID, err)
+		return nil
+	}
+
+	if saveErr == nil {
+		o.remove(ctx, job.ID)
+	}
+	return nil
+}
+
+// Run reenvia periodicamente os jobs pendentes no outbox até ctx ser
+// cancelado.
+func (o *Outbox) Run(ctx context.Context) {
+	log.Println("📮 Relay do outbox iniciado")
+
+	ticker := time.NewTicker(outboxRelayInterval)
+	defer ticker.Stop()
+
+	for {
+		o.relay(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publica as entradas pendentes mais antigas que outboxRelayMinAge e
+// devolve quantas foram confirmadas.
+func (o *Outbox) relay(ctx context.Context) int {
+	entries, err := o.store.ListOutboxEntries(ctx)
+	if err != nil {
+		log.Printf("Erro ao ler outbox: %v", err)
+		return 0
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].EnqueuedAt.Before(entries[j].EnqueuedAt)
+	})
+
+	published := 0
+	for i := range entries {
+		entry := &entries[i]
+		// As entradas estão em ordem de chegada: as restantes são mais novas.
+		if ctx.Err() != nil || time.Since(entry.EnqueuedAt) < outboxRelayMinAge {
+			break
+		}
+
+		if err := o.publisher.PublishVideoProcessingJob(&entry.Job); err != nil {
+			entry.Attempts++
+			log.Printf("⚠️ Falha ao reenviar job %s do outbox (tentativa %d): %v", entry.Job.ID, entry.Attempts, err)
+			if saveErr := o.store.SaveOutboxEntry(ctx, entry); saveErr != nil {
+				log.Printf("Erro ao atualizar job %s no outbox: %v", entry.Job.ID, saveErr)
+			}
+			continue
+		}
+
+		log.Printf("📮 Job reenviado do outbox: JobID=%s, VideoID=%d", entry.Job.ID, entry.Job.VideoID)
+		o.remove(ctx, entry.Job.ID)
+		published++
+	}
+
+	return published
+}
+
+func (o *Outbox) remove(ctx context.Context, jobID string) {
+	if err := o.store.DeleteOutboxEntry(ctx, jobID); err != nil {
+		log.Printf("Erro ao remover job %s do outbox: %v", jobID, err)
+	}
+}
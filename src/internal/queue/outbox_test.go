@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"src/internal/cache"
+	"src/internal/models"
+	"testing"
+	"time"
+)
+
+type fakeOutboxStore struct {
+	entries map[string]cache.OutboxEntry
+	saveErr error
+}
+
+func newFakeOutboxStore() *fakeOutboxStore {
+	return &fakeOutboxStore{entries: make(map[string]cache.OutboxEntry)}
+}
+
+func (f *fakeOutboxStore) SaveOutboxEntry(ctx context.Context, entry *cache.OutboxEntry) error {
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	f.entries[entry.Job.ID] = *entry
+	return nil
+}
+
+func (f *fakeOutboxStore) DeleteOutboxEntry(ctx context.Context, jobID string) error {
+	delete(f.entries, jobID)
+	return nil
+}
+
+func (f *fakeOutboxStore) ListOutboxEntries(ctx context.Context) ([]cache.OutboxEntry, error) {
+	var entries []cache.OutboxEntry
+	for _, entry := range f.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func newTestOutbox() (*Outbox, *fakeOutboxStore, *fakeJobPublisher) {
+	store := newFakeOutboxStore()
+	publisher := &fakeJobPublisher{}
+	return &Outbox{store: store, publisher: publisher}, store, publisher
+}
+
+func TestOutboxEnqueuePublishesAndClearsEntry(t *testing.T) {
+	outbox, store, publisher := newTestOutbox()
+
+	if err := outbox.Enqueue(context.Background(), &models.VideoProcessingJob{ID: "job_1", VideoID: 42}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(publisher.jobs) != 1 {
+		t.Errorf("publicados = %d, esperado 1", len(publisher.jobs))
+	}
+	if len(store.entries) != 0 {
+		t.Errorf("job confirmado não deveria continuar no outbox: %v", store.entries)
+	}
+}
+
+func TestOutboxEnqueueKeepsUnpublishedJob(t *testing.T) {
+	outbox, store, publisher := newTestOutbox()
+	publisher.err = errors.New("RabbitMQ indisponível")
+
+	if err := outbox.Enqueue(context.Background(), &models.VideoProcessingJob{ID: "job_1", VideoID: 42}); err != nil {
+		t.Fatalf("job guardado no outbox não deveria falhar o upload: %v", err)
+	}
+	if _, ok := store.entries["job_1"]; !ok {
+		t.Fatal("job não publicado deveria continuar no outbox")
+	}
+
+	store.saveErr = errors.New("redis indisponível")
+	if err := outbox.Enqueue(context.Background(), &models.VideoProcessingJob{ID: "job_2", VideoID: 43}); err == nil {
+		t.Error("erro esperado quando o job não pode ser publicado nem guardado")
+	}
+}
+
+func TestOutboxRelayPublishesPendingEntries(t *testing.T) {
+	outbox, store, publisher := newTestOutbox()
+	store.entries["antigo"] = cache.OutboxEntry{
+		Job:        models.VideoProcessingJob{ID: "antigo", VideoID: 1},
+		EnqueuedAt: time.Now().Add(-time.Minute),
+	}
+	store.entries["recente"] = cache.OutboxEntry{
+		Job:        models.VideoProcessingJob{ID: "recente", VideoID: 2},
+		EnqueuedAt: time.Now(),
+	}
+
+	publisher.err = errors.New("RabbitMQ indisponível")
+	if published := outbox.relay(context.Background()); published != 0 {
+		t.Errorf("publicados = %d com o RabbitMQ fora do ar, esperado 0", published)
+	}
+	if entry := store.entries["antigo"]; entry.Attempts != 1 {
+		t.Errorf("tentativas = %d, esperado 1", entry.Attempts)
+	}
+
+	publisher.err = nil
+	if published := outbox.relay(context.Background()); published != 1 {
+		t.Errorf("publicados = %d, esperado 1", published)
+	}
+	if len(publisher.jobs) != 1 || publisher.jobs[0].ID != "antigo" {
+		t.Errorf("apenas a entrada antiga deveria ser reenviada, publicados %v", publisher.jobs)
+	}
+	if _, ok := store.entries["recente"]; !ok || len(store.entries) != 1 {
+		t.Errorf("apenas a entrada recente deveria continuar no outbox: %v", store.entries)
+	}
+}
@@ -8,13 +8,15 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// Publisher publica pelo canal principal do RabbitMQClient, acompanhando as
+// reconexões.
 type Publisher struct {
-	channel *amqp.Channel
+	channel channelPublisher
 }
 
-func NewPublisher(channel *amqp.Channel) *Publisher {
+func NewPublisher(client *RabbitMQClient) *Publisher {
 	return &Publisher{
-		channel: channel,
+		channel: client,
 	}
 }
 
@@ -30,8 +32,9 @@ func (p *Publisher) PublishVideoProcessingJob(job *models.VideoProcessingJob) er
 		false,
 		false,
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        jobBytes,
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         jobBytes,
 		},
 	)
 
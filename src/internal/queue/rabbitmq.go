@@ -1,38 +1,101 @@
 package queue
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"src/internal/config"
+	"src/internal/models"
+	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-type RabbitMQClient struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	config  *config.Config
+const (
+	// Atraso entre as tentativas de reconexão, dobrado a cada falha.
+	reconnectMinDelay = 1 * time.Second
+	reconnectMaxDelay = 30 * time.Second
+
+	// Tempo que Publish aguarda uma reconexão em andamento antes de falhar.
+	publishReconnectWait = 5 * time.Second
+
+	// Tempo que Publish aguarda a confirmação do broker.
+	publishConfirmTimeout = 10 * time.Second
+)
+
+var errClientClosed = errors.New("cliente RabbitMQ encerrado")
+
+// amqpChannel é o subconjunto de *amqp.Channel usado pelo cliente, pelo
+// consumer e pela DLQ.
+type amqpChannel interface {
+	Confirm(noWait bool) error
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	QueuePurge(name string, noWait bool) (int, error)
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	PublishWithDeferredConfirm(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) (*amqp.DeferredConfirmation, error)
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	IsClosed() bool
+	Close() error
 }
 
-func NewRabbitMQClient(cfg *config.Config) (*RabbitMQClient, error) {
-	conn, err := amqp.Dial(cfg.RabbitMQURL)
+// amqpConnection é o subconjunto de *amqp.Connection usado pelo cliente.
+type amqpConnection interface {
+	Channel() (amqpChannel, error)
+	NotifyClose(receiver chan *amqp.Error) chan *amqp.Error
+	IsClosed() bool
+	Close() error
+}
+
+// amqpConn adapta *amqp.Connection a amqpConnection.
+type amqpConn struct {
+	*amqp.Connection
+}
+
+func (c amqpConn) Channel() (amqpChannel, error) {
+	channel, err := c.Connection.Channel()
 	if err != nil {
 		return nil, err
 	}
+	return channel, nil
+}
 
-	ch, err := conn.Channel()
+func dialAMQP(url string) (amqpConnection, error) {
+	conn, err := amqp.Dial(url)
 	if err != nil {
-		conn.Close()
 		return nil, err
 	}
+	return amqpConn{conn}, nil
+}
 
-	client := &RabbitMQClient{
-		conn:    conn,
-		channel: ch,
-		config:  cfg,
-	}
+// RabbitMQClient mantém a conexão e o canal principal com o RabbitMQ. Se o
+// broker derrubar a conexão ou o canal, um supervisor reconecta com backoff
+// exponencial e declara as filas novamente.
+type RabbitMQClient struct {
+	config *config.Config
+	dial   func(url string) (amqpConnection, error)
+	// after aguarda o atraso entre as tentativas de reconexão.
+	after func(d time.Duration) <-chan time.Time
 
-	if err := client.declareQueues(); err != nil {
-		conn.Close()
+	mu      sync.RWMutex
+	conn    amqpConnection
+	channel amqpChannel
+	// changed é fechado (e substituído) a cada novo canal disponível.
+	changed chan struct{}
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewRabbitMQClient(cfg *config.Config) (*RabbitMQClient, error) {
+	client := newRabbitMQClient(cfg, dialAMQP)
+	if err := client.start(); err != nil {
 		return nil, err
 	}
 
@@ -40,9 +103,148 @@ func NewRabbitMQClient(cfg *config.Config) (*RabbitMQClient, error) {
 	return client, nil
 }
 
-func (r *RabbitMQClient) declareQueues() error {
-	_, err := r.channel.QueueDeclare(
-		"input_processing_queue",
+func newRabbitMQClient(cfg *config.Config, dial func(url string) (amqpConnection, error)) *RabbitMQClient {
+	return &RabbitMQClient{
+		config:  cfg,
+		dial:    dial,
+		after:   time.After,
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// start faz a primeira conexão e inicia o supervisor.
+func (r *RabbitMQClient) start() error {
+	if err := r.connect(); err != nil {
+		return err
+	}
+	go r.supervise()
+	return nil
+}
+
+// connect abre um canal na conexão atual, discando de novo se ela estiver
+// fechada, ativa as confirmações de publicação e declara as filas antes de
+// publicar o novo canal.
+func (r *RabbitMQClient) connect() error {
+	r.mu.RLock()
+	conn := r.conn
+	r.mu.RUnlock()
+
+	dialed := false
+	if conn == nil || conn.IsClosed() {
+		var err error
+		conn, err = r.dial(r.config.RabbitMQURL)
+		if err != nil {
+			return err
+		}
+		dialed = true
+	}
+
+	ch, err := conn.Channel()
+	if err == nil {
+		err = prepareChannel(ch)
+		if err != nil {
+			ch.Close()
+		}
+	}
+	if err != nil {
+		if dialed {
+			conn.Close()
+		}
+		return err
+	}
+
+	r.setChannel(conn, ch)
+	return nil
+}
+
+// setChannel publica o novo canal e acorda quem aguarda em WaitForChannel.
+func (r *RabbitMQClient) setChannel(conn amqpConnection, ch amqpChannel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conn = conn
+	r.channel = ch
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// supervise aguarda o fechamento da conexão ou do canal e reconecta, até
+// que Close seja chamado.
+func (r *RabbitMQClient) supervise() {
+	for {
+		r.mu.RLock()
+		conn, ch := r.conn, r.channel
+		r.mu.RUnlock()
+
+		connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+		chanClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-r.done:
+			return
+		case err := <-connClosed:
+			if r.closed() {
+				return
+			}
+			log.Printf("⚠️ Conexão com o RabbitMQ perdida: %v", err)
+		case err := <-chanClosed:
+			if r.closed() {
+				return
+			}
+			log.Printf("⚠️ Canal do RabbitMQ fechado: %v", err)
+		}
+
+		if !r.reconnect() {
+			return
+		}
+	}
+}
+
+// reconnect tenta reconectar com backoff exponencial. Devolve false se o
+// cliente for encerrado antes de conseguir.
+func (r *RabbitMQClient) reconnect() bool {
+	delay := reconnectMinDelay
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-r.done:
+			return false
+		case <-r.after(delay):
+		}
+
+		err := r.connect()
+		if err == nil {
+			log.Printf("🔌 Reconectado ao RabbitMQ após %d tentativas", attempt)
+			return true
+		}
+
+		delay *= 2
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+		log.Printf("⚠️ Tentativa %d de reconexão ao RabbitMQ falhou: %v (nova tentativa em %s)", attempt, err, delay)
+	}
+}
+
+func (r *RabbitMQClient) closed() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func prepareChannel(channel amqpChannel) error {
+	if err := channel.Confirm(false); err != nil {
+		return fmt.Errorf("erro ao ativar confirmações de publicação: %w", err)
+	}
+	return declareQueues(channel)
+}
+
+func declareQueues(channel amqpChannel) error {
+	_, err := channel.QueueDeclare(
+		models.InputProcessingQueue,
 		true,
 		false,
 		false,
@@ -53,10 +255,15 @@ func (r *RabbitMQClient) declareQueues() error {
 		return err
 	}
 
-	return nil
+	return declareRetryTopology(channel)
 }
 
 func (r *RabbitMQClient) Close() error {
+	r.closeOnce.Do(func() { close(r.done) })
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	if r.channel != nil {
 		r.channel.Close()
 	}
@@ -66,6 +273,71 @@ func (r *RabbitMQClient) Close() error {
 	return nil
 }
 
-func (r *RabbitMQClient) GetChannel() *amqp.Channel {
+// GetChannel devolve o canal principal atual, que pode estar fechado durante
+// uma reconexão.
+func (r *RabbitMQClient) GetChannel() amqpChannel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.channel
 }
+
+// WaitForChannel aguarda um canal principal aberto e diferente de stale (o
+// canal que o chamador viu fechar; nil aceita o atual).
+func (r *RabbitMQClient) WaitForChannel(ctx context.Context, stale amqpChannel) (amqpChannel, error) {
+	for {
+		r.mu.RLock()
+		ch, changed := r.channel, r.changed
+		r.mu.RUnlock()
+
+		if ch != stale && !ch.IsClosed() {
+			return ch, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-r.done:
+			return nil, errClientClosed
+		case <-changed:
+		}
+	}
+}
+
+// Publish publica no canal principal atual, aguardando até
+// publishReconnectWait se uma reconexão estiver em andamento, e só retorna
+// sem erro depois que o broker confirma a mensagem.
+func (r *RabbitMQClient) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	ctx, cancel := context.WithTimeout(context.Background(), publishReconnectWait)
+	defer cancel()
+
+	channel, err := r.WaitForChannel(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("RabbitMQ indisponível: %w", err)
+	}
+
+	confirmation, err := channel.PublishWithDeferredConfirm(exchange, key, mandatory, immediate, msg)
+	if err != nil {
+		return err
+	}
+
+	confirmCtx, cancelConfirm := context.WithTimeout(context.Background(), publishConfirmTimeout)
+	defer cancelConfirm()
+
+	acked, err := confirmation.WaitContext(confirmCtx)
+	if err != nil {
+		return fmt.Errorf("confirmação do RabbitMQ não recebida: %w", err)
+	}
+	if !acked {
+		return fmt.Errorf("RabbitMQ recusou a mensagem")
+	}
+	return nil
+}
+
+// OpenChannel abre um canal dedicado na conexão atual, para operações que
+// não devem interferir nas entregas pendentes do canal principal.
+func (r *RabbitMQClient) OpenChannel() (amqpChannel, error) {
+	r.mu.RLock()
+	conn := r.conn
+	r.mu.RUnlock()
+	return conn.Channel()
+}
@@ -0,0 +1,116 @@
+package queue
+
+import (
+	"fmt"
+	"src/internal/models"
+	"strings"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// retryDelays define o atraso antes de cada nova tentativa. Cada atraso tem
+// sua própria fila com TTL, que devolve a mensagem para a fila de entrada
+// via dead-letter quando o prazo expira.
+var retryDelays = []time.Duration{10 * time.Second, 60 * time.Second}
+
+const (
+	maxRetries = 3
+
+	// Uma publicação na fila de retry ou na DLQ que falha é repetida até
+	// publishAttempts vezes, com atraso dobrando de publishRetryMinDelay até
+	// publishRetryMaxDelay, antes de a entrega voltar para a fila de entrada.
+	publishAttempts      = 6
+	publishRetryMinDelay = 1 * time.Second
+	publishRetryMaxDelay = 10 * time.Second
+
+	headerFailureReason = "x-failure-reason"
+	headerFailedAt      = "x-failed-at"
+	headerAttempts      = "x-attempts"
+	headerErrorClass    = "x-error-class"
+)
+
+// channelPublisher é o subconjunto de *amqp.Channel usado para reenviar
+// mensagens às filas de retry e à DLQ.
+type channelPublisher interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+func retryQueueName(level int) string {
+	return fmt.Sprintf("%s.retry.%d", models.InputProcessingQueue, level)
+}
+
+func retryRoutingKey(level int) string {
+	return fmt.Sprintf("retry.%d", level)
+}
+
+// retryDelay devolve o atraso e a routing key da nova tentativa após a
+// falha de attempt (a partir de 1).
+func retryDelay(attempt int) (time.Duration, string) {
+	level := attempt
+	if level > len(retryDelays) {
+		level = len(retryDelays)
+	}
+	return retryDelays[level-1], retryRoutingKey(level)
+}
+
+// deliveryAttempt calcula o número da tentativa atual a partir do cabeçalho
+// x-death, que o RabbitMQ incrementa a cada passagem por uma fila de retry.
+func deliveryAttempt(headers amqp.Table) int {
+	deaths, ok := headers["x-death"].([]interface{})
+	if !ok {
+		return 1
+	}
+
+	retries := 0
+	for _, entry := range deaths {
+		death, ok := entry.(amqp.Table)
+		if !ok {
+			continue
+		}
+		queue, _ := death["queue"].(string)
+		if !strings.HasPrefix(queue, models.InputProcessingQueue+".retry.") {
+			continue
+		}
+		if count, ok := death["count"].(int64); ok {
+			retries += int(count)
+		}
+	}
+
+	return retries + 1
+}
+
+// declareRetryTopology declara a exchange de retry com uma fila de atraso
+// por nível e a exchange de dead-letter com a DLQ.
+func declareRetryTopology(channel amqpChannel) error {
+	if err := channel.ExchangeDeclare(models.RetryExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("erro ao declarar exchange de retry: %w", err)
+	}
+
+	for i, delay := range retryDelays {
+		level := i + 1
+		_, err := channel.QueueDeclare(retryQueueName(level), true, false, false, false, amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": models.InputProcessingQueue,
+		})
+		if err != nil {
+			return fmt.Errorf("erro ao declarar fila de retry %d: %w", level, err)
+		}
+		if err := channel.QueueBind(retryQueueName(level), retryRoutingKey(level), models.RetryExchange, false, nil); err != nil {
+			return fmt.Errorf("erro ao vincular fila de retry %d: %w", level, err)
+		}
+	}
+
+	if err := channel.ExchangeDeclare(models.DeadLetterExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("erro ao declarar exchange de dead-letter: %w", err)
+	}
+	if _, err := channel.QueueDeclare(models.DeadLetterQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("erro ao declarar DLQ: %w", err)
+	}
+	if err := channel.QueueBind(models.DeadLetterQueue, models.InputProcessingQueue, models.DeadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("erro ao vincular DLQ: %w", err)
+	}
+
+	return nil
+}
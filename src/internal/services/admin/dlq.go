@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+	"src/internal/cache"
+	"src/internal/models"
+	"src/internal/queue"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultListLimit = 100
+
+type DeadLetterResponse struct {
+	Success bool                  `json:"success"`
+	Message string                `json:"message,omitempty"`
+	Count   int                   `json:"count"`
+	Jobs    []queue.DeadLetterJob `json:"jobs,omitempty"`
+}
+
+func HandleListDeadLetters(c *gin.Context, dlq *queue.DeadLetterQueue) {
+	limit := defaultListLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, DeadLetterResponse{
+				Success: false,
+				Message: "limit deve ser um número inteiro positivo",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	jobs, err := dlq.List(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, DeadLetterResponse{
+			Success: false,
+			Message: "Erro ao listar jobs com falha: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeadLetterResponse{
+		Success: true,
+		Count:   len(jobs),
+		Jobs:    jobs,
+	})
+}
+
+// HandleReplayDeadLetters reenvia para processamento o job do parâmetro
+// job_id ou, sem ele, todos os jobs da DLQ.
+func HandleReplayDeadLetters(c *gin.Context, dlq *queue.DeadLetterQueue, redisClient *cache.RedisClient) {
+	jobID := c.Param("job_id")
+
+	replayed, err := dlq.Replay(jobID)
+	for i := range replayed {
+		resetProcessingStatus(c, redisClient, &replayed[i])
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, DeadLetterResponse{
+			Success: false,
+			Message: "Erro ao reenviar jobs: " + err.Error(),
+			Count:   len(replayed),
+		})
+		return
+	}
+
+	if jobID != "" && len(replayed) == 0 {
+		c.JSON(http.StatusNotFound, DeadLetterResponse{
+			Success: false,
+			Message: "Job não encontrado na DLQ",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeadLetterResponse{
+		Success: true,
+		Message: "Jobs reenviados para processamento",
+		Count:   len(replayed),
+	})
+}
+
+// HandlePurgeDeadLetters descarta o job do parâmetro job_id ou, sem ele,
+// todos os jobs da DLQ.
+func HandlePurgeDeadLetters(c *gin.Context, dlq *queue.DeadLetterQueue) {
+	jobID := c.Param("job_id")
+
+	var removed int
+	var err error
+	if jobID != "" {
+		removed, err = dlq.Remove(jobID)
+	} else {
+		removed, err = dlq.Purge()
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, DeadLetterResponse{
+			Success: false,
+			Message: "Erro ao remover jobs: " + err.Error(),
+		})
+		return
+	}
+
+	if jobID != "" && removed == 0 {
+		c.JSON(http.StatusNotFound, DeadLetterResponse{
+			Success: false,
+			Message: "Job não encontrado na DLQ",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeadLetterResponse{
+		Success: true,
+		Message: "Jobs removidos da DLQ",
+		Count:   removed,
+	})
+}
+
+func resetProcessingStatus(c *gin.Context, redisClient *cache.RedisClient, job *models.VideoProcessingJob) {
+	processingStatus := &cache.ProcessingStatus{
+		VideoID:   job.VideoID,
+		UserID:    job.UserID,
+		JobID:     job.ID,
+		Status:    models.StatusPending,
+		Message:   "Reprocessamento solicitado, aguardando processamento",
+		UpdatedAt: time.Now(),
+	}
+	if err := redisClient.SetProcessingStatus(c.Request.Context(), processingStatus); err != nil {
+		log.Printf("Erro ao salvar status de processamento no cache: %v", err)
+	}
+}
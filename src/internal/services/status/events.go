@@ -0,0 +1,78 @@
+package status
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"src/internal/cache"
+	"src/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+func HandleStatusEvents(c *gin.Context, redisClient *cache.RedisClient) {
+	current, ok := loadProcessingStatus(c, redisClient)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	updates, unsubscribe, err := redisClient.SubscribeProcessingStatus(ctx, current.VideoID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, StatusResponse{
+			Success: false,
+			Message: "Erro ao assinar eventos de status: " + err.Error(),
+		})
+		return
+	}
+	defer func() {
+		if err := unsubscribe(); err != nil {
+			log.Printf("Erro ao cancelar assinatura de status: %v", err)
+		}
+	}()
+
+	// Relê o status depois de assinar para não perder uma transição ocorrida no meio.
+	if latest, err := redisClient.GetProcessingStatus(ctx, current.VideoID); err == nil && latest != nil {
+		current = latest
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent(current.Status, current)
+	c.Writer.Flush()
+	if isFinalStatus(current.Status) {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return false
+			}
+			return true
+		case update, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent(update.Status, update)
+			return !isFinalStatus(update.Status)
+		}
+	})
+}
+
+func isFinalStatus(status string) bool {
+	return status == models.StatusCompleted || status == models.StatusFailed
+}
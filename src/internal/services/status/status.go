@@ -0,0 +1,68 @@
+package status
+
+import (
+	"net/http"
+	"src/internal/cache"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatusResponse struct {
+	Success bool                    `json:"success"`
+	Message string                  `json:"message,omitempty"`
+	Status  *cache.ProcessingStatus `json:"status,omitempty"`
+}
+
+func HandleGetStatus(c *gin.Context, redisClient *cache.RedisClient) {
+	processingStatus, ok := loadProcessingStatus(c, redisClient)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, StatusResponse{
+		Success: true,
+		Status:  processingStatus,
+	})
+}
+
+func loadProcessingStatus(c *gin.Context, redisClient *cache.RedisClient) (*cache.ProcessingStatus, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, StatusResponse{
+			Success: false,
+			Message: "Usuário não autenticado",
+		})
+		return nil, false
+	}
+
+	videoID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, StatusResponse{
+			Success: false,
+			Message: "ID de vídeo inválido",
+		})
+		return nil, false
+	}
+
+	processingStatus, err := redisClient.GetProcessingStatus(c.Request.Context(), uint(videoID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, StatusResponse{
+			Success: false,
+			Message: "Erro ao buscar status: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	// Status de outro usuário é tratado como inexistente para não expor quais IDs existem.
+	if processingStatus == nil || processingStatus.UserID != uint(userID.(int)) {
+		c.JSON(http.StatusNotFound, StatusResponse{
+			Success: false,
+			Message: "Status de processamento não encontrado",
+		})
+		return nil, false
+	}
+
+	return processingStatus, true
+}
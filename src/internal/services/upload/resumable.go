@@ -0,0 +1,513 @@
+package upload
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"src/internal/api"
+	"src/internal/cache"
+	"src/internal/models"
+	"src/internal/services/video_processing"
+	"src/internal/storage"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// O MinIO exige partes de no mínimo 5 MiB, exceto a última.
+	MinChunkSize = 5 * 1024 * 1024
+	MaxChunks    = 10000
+
+	UploadOffsetHeader = "Upload-Offset"
+	UploadLengthHeader = "Upload-Length"
+
+	// A sessão é renovada a cada parte enviada, então um multipart sem
+	// atividade há mais que o TTL da sessão (com folga) foi abandonado.
+	staleUploadAge          = cache.UploadTTL + time.Hour
+	staleUploadScanInterval = time.Hour
+)
+
+// MultipartStore é o subconjunto do MinioClient usado pelo upload retomável.
+type MultipartStore interface {
+	NewMultipartUpload(ctx context.Context, objectName string) (string, error)
+	UploadPart(ctx context.Context, objectName, uploadID string, partNumber int, data io.Reader, size int64) (*storage.UploadedPart, error)
+	CompleteMultipartUpload(ctx context.Context, objectName, uploadID string, parts []storage.UploadedPart) (string, error)
+	AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error
+}
+
+// StaleUploadStore é o subconjunto do MinioClient usado para abortar os
+// uploads multipart abandonados.
+type StaleUploadStore interface {
+	ListMultipartUploads(ctx context.Context) ([]storage.MultipartUpload, error)
+	AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error
+}
+
+// SessionStore é o subconjunto do RedisClient usado pelo upload retomável.
+type SessionStore interface {
+	StatusStore
+	GetUploadSession(ctx context.Context, uploadID string) (*cache.UploadSession, error)
+	SetUploadSession(ctx context.Context, session *cache.UploadSession) error
+	DeleteUploadSession(ctx context.Context, uploadID string) error
+	AcquireUploadLock(ctx context.Context, uploadID, token string) (bool, error)
+	ReleaseUploadLock(ctx context.Context, uploadID, token string) error
+}
+
+type ResumableInitRequest struct {
+	FileName string                    `json:"file_name" binding:"required"`
+	Size     int64                     `json:"size" binding:"required"`
+	Options  *models.ExtractionOptions `json:"options,omitempty"`
+}
+
+type ResumableUploadResponse struct {
+	Success      bool   `json:"success"`
+	Message      string `json:"message"`
+	UploadID     string `json:"upload_id,omitempty"`
+	Offset       int64  `json:"offset"`
+	Size         int64  `json:"size,omitempty"`
+	MinChunkSize int64  `json:"min_chunk_size,omitempty"`
+}
+
+func HandleResumableInit(c *gin.Context, minioClient MultipartStore, redisClient SessionStore) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ResumableInitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ResumableUploadResponse{
+			Success: false,
+			Message: "Requisição inválida: " + err.Error(),
+		})
+		return
+	}
+
+	if !isValidVideoFile(req.FileName) {
+		c.JSON(http.StatusBadRequest, ResumableUploadResponse{
+			Success: false,
+			Message: "Formato de arquivo não suportado. Use: mp4, avi, mov, mkv",
+		})
+		return
+	}
+
+	if req.Size <= 0 || req.Size > MinChunkSize*MaxChunks {
+		c.JSON(http.StatusBadRequest, ResumableUploadResponse{
+			Success: false,
+			Message: "Tamanho de arquivo inválido",
+		})
+		return
+	}
+
+	if req.Options != nil {
+		options, err := video_processing.NormalizeExtractionOptions(req.Options)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ResumableUploadResponse{
+				Success: false,
+				Message: "Opções de extração inválidas: " + err.Error(),
+			})
+			return
+		}
+		req.Options = options
+	}
+
+	timestamp := time.Now().Format("20060102_150405")
+	fileName := fmt.Sprintf("%s_%s", timestamp, req.FileName)
+	objectName := fmt.Sprintf("%d/input/%s", userID, fileName)
+
+	multipartID, err := minioClient.NewMultipartUpload(c.Request.Context(), objectName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResumableUploadResponse{
+			Success: false,
+			Message: "Erro ao iniciar upload no MinIO: " + err.Error(),
+		})
+		return
+	}
+
+	session := &cache.UploadSession{
+		ID:         generateUploadID(),
+		UserID:     userID,
+		FileName:   req.FileName,
+		ObjectName: objectName,
+		UploadID:   multipartID,
+		Size:       req.Size,
+		Options:    req.Options,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	if err := redisClient.SetUploadSession(c.Request.Context(), session); err != nil {
+		if abortErr := minioClient.AbortMultipartUpload(c.Request.Context(), objectName, multipartID); abortErr != nil {
+			log.Printf("Erro ao abortar upload multipart: %v", abortErr)
+		}
+		c.JSON(http.StatusInternalServerError, ResumableUploadResponse{
+			Success: false,
+			Message: "Erro ao salvar sessão de upload: " + err.Error(),
+		})
+		return
+	}
+
+	log.Printf("📦 Upload retomável iniciado: UploadID=%s, UserID=%d, Size=%d", session.ID, userID, session.Size)
+
+	c.Header("Location", fmt.Sprintf("/upload/video/resumable/%s", session.ID))
+	c.JSON(http.StatusCreated, ResumableUploadResponse{
+		Success:      true,
+		Message:      "Upload iniciado",
+		UploadID:     session.ID,
+		Offset:       0,
+		Size:         session.Size,
+		MinChunkSize: MinChunkSize,
+	})
+}
+
+func HandleResumableStatus(c *gin.Context, redisClient SessionStore) {
+	session, ok := loadUploadSession(c, redisClient)
+	if !ok {
+		return
+	}
+
+	c.Header(UploadOffsetHeader, strconv.FormatInt(session.Offset, 10))
+	c.Header(UploadLengthHeader, strconv.FormatInt(session.Size, 10))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, ResumableUploadResponse{
+		Success:      true,
+		Message:      "Upload em andamento",
+		UploadID:     session.ID,
+		Offset:       session.Offset,
+		Size:         session.Size,
+		MinChunkSize: MinChunkSize,
+	})
+}
+
+// HandleResumableChunk grava uma parte do arquivo. A última parte conclui o
+// upload; se a conclusão falhar, a sessão fica com o offset no fim do
+// arquivo e um PATCH sem corpo com esse offset repete apenas a conclusão.
+func HandleResumableChunk(c *gin.Context, minioClient MultipartStore, redisClient SessionStore, videoAPI api.VideoAPI, jobs JobQueue) {
+	session, ok := loadUploadSession(c, redisClient)
+	if !ok {
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader(UploadOffsetHeader), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ResumableUploadResponse{
+			Success: false,
+			Message: "Header Upload-Offset ausente ou inválido",
+		})
+		return
+	}
+
+	chunkSize := c.Request.ContentLength
+	if chunkSize < 0 || (chunkSize == 0 && offset != session.Size) {
+		c.JSON(http.StatusLengthRequired, ResumableUploadResponse{
+			Success: false,
+			Message: "Content-Length é obrigatório para envio de partes",
+		})
+		return
+	}
+
+	uploadID := session.ID
+	lockToken := generateLockToken()
+	locked, err := redisClient.AcquireUploadLock(c.Request.Context(), uploadID, lockToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResumableUploadResponse{
+			Success: false,
+			Message: "Erro ao bloquear sessão de upload: " + err.Error(),
+		})
+		return
+	}
+	if !locked {
+		c.JSON(http.StatusConflict, ResumableUploadResponse{
+			Success: false,
+			Message: "Outra parte deste upload está sendo enviada",
+			Offset:  session.Offset,
+		})
+		return
+	}
+	defer func() {
+		// A liberação não pode ser cancelada pela desconexão do cliente, ou o
+		// upload ficaria bloqueado até o lock expirar.
+		if err := redisClient.ReleaseUploadLock(context.WithoutCancel(c.Request.Context()), uploadID, lockToken); err != nil {
+			log.Printf("Erro ao liberar lock do upload %s: %v", uploadID, err)
+		}
+	}()
+
+	// Relê a sessão já com o lock para não perder partes enviadas em paralelo.
+	session, err = redisClient.GetUploadSession(c.Request.Context(), uploadID)
+	if err != nil || session == nil {
+		c.JSON(http.StatusNotFound, ResumableUploadResponse{
+			Success: false,
+			Message: "Sessão de upload não encontrada",
+		})
+		return
+	}
+
+	if offset != session.Offset {
+		c.Header(UploadOffsetHeader, strconv.FormatInt(session.Offset, 10))
+		c.JSON(http.StatusConflict, ResumableUploadResponse{
+			Success: false,
+			Message: "Upload-Offset não corresponde ao offset atual",
+			Offset:  session.Offset,
+		})
+		return
+	}
+
+	if session.Offset == session.Size && chunkSize == 0 {
+		finalizeResumableUpload(c, minioClient, redisClient, videoAPI, jobs, session)
+		return
+	}
+
+	isLastChunk := session.Offset+chunkSize == session.Size
+	if session.Offset+chunkSize > session.Size {
+		c.JSON(http.StatusRequestEntityTooLarge, ResumableUploadResponse{
+			Success: false,
+			Message: "Parte excede o tamanho declarado do arquivo",
+			Offset:  session.Offset,
+		})
+		return
+	}
+	if !isLastChunk && chunkSize < MinChunkSize {
+		c.JSON(http.StatusBadRequest, ResumableUploadResponse{
+			Success: false,
+			Message: fmt.Sprintf("Partes intermediárias devem ter no mínimo %d bytes", MinChunkSize),
+			Offset:  session.Offset,
+		})
+		return
+	}
+	if len(session.Parts) >= MaxChunks {
+		c.JSON(http.StatusBadRequest, ResumableUploadResponse{
+			Success: false,
+			Message: "Número máximo de partes atingido",
+			Offset:  session.Offset,
+		})
+		return
+	}
+
+	partNumber := len(session.Parts) + 1
+	body := io.LimitReader(c.Request.Body, chunkSize)
+
+	part, err := minioClient.UploadPart(c.Request.Context(), session.ObjectName, session.UploadID, partNumber, body, chunkSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResumableUploadResponse{
+			Success: false,
+			Message: "Erro ao enviar parte para MinIO: " + err.Error(),
+			Offset:  session.Offset,
+		})
+		return
+	}
+
+	session.Parts = append(session.Parts, cache.UploadPart{
+		PartNumber: part.PartNumber,
+		ETag:       part.ETag,
+	})
+	session.Offset += chunkSize
+	session.UpdatedAt = time.Now()
+
+	if err := redisClient.SetUploadSession(c.Request.Context(), session); err != nil {
+		c.JSON(http.StatusInternalServerError, ResumableUploadResponse{
+			Success: false,
+			Message: "Erro ao salvar progresso do upload: " + err.Error(),
+		})
+		return
+	}
+
+	c.Header(UploadOffsetHeader, strconv.FormatInt(session.Offset, 10))
+
+	if !isLastChunk {
+		c.JSON(http.StatusOK, ResumableUploadResponse{
+			Success:  true,
+			Message:  fmt.Sprintf("Parte %d recebida", partNumber),
+			UploadID: session.ID,
+			Offset:   session.Offset,
+			Size:     session.Size,
+		})
+		return
+	}
+
+	finalizeResumableUpload(c, minioClient, redisClient, videoAPI, jobs, session)
+}
+
+func HandleResumableAbort(c *gin.Context, minioClient MultipartStore, redisClient SessionStore) {
+	session, ok := loadUploadSession(c, redisClient)
+	if !ok {
+		return
+	}
+
+	if err := minioClient.AbortMultipartUpload(c.Request.Context(), session.ObjectName, session.UploadID); err != nil {
+		log.Printf("Erro ao abortar upload multipart: %v", err)
+	}
+
+	if err := redisClient.DeleteUploadSession(c.Request.Context(), session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, ResumableUploadResponse{
+			Success: false,
+			Message: "Erro ao remover sessão de upload: " + err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// finalizeResumableUpload conclui o multipart no MinIO e registra o vídeo.
+// A sessão só é removida quando não há mais o que repetir: em caso de falha
+// o cliente pode reenviar um PATCH vazio para tentar de novo.
+func finalizeResumableUpload(c *gin.Context, minioClient MultipartStore, redisClient SessionStore, videoAPI api.VideoAPI, jobs JobQueue, session *cache.UploadSession) {
+	if session.URL == "" {
+		parts := make([]storage.UploadedPart, len(session.Parts))
+		for i, part := range session.Parts {
+			parts[i] = storage.UploadedPart{
+				PartNumber: part.PartNumber,
+				ETag:       part.ETag,
+			}
+		}
+
+		url, err := minioClient.CompleteMultipartUpload(c.Request.Context(), session.ObjectName, session.UploadID, parts)
+		if err != nil {
+			c.Header(UploadOffsetHeader, strconv.FormatInt(session.Offset, 10))
+			c.JSON(http.StatusInternalServerError, UploadResponse{
+				Success: false,
+				Message: "Erro ao concluir upload no MinIO, tente novamente: " + err.Error(),
+			})
+			return
+		}
+
+		session.URL = url
+		session.UpdatedAt = time.Now()
+		if err := redisClient.SetUploadSession(c.Request.Context(), session); err != nil {
+			log.Printf("Erro ao salvar conclusão do upload %s: %v", session.ID, err)
+		}
+
+		log.Printf("✅ Upload retomável concluído: UploadID=%s, Parts=%d", session.ID, len(parts))
+	}
+
+	videoID, err := registerVideo(c, videoAPI, redisClient, jobs, session.FileName, session.URL, session.UserID, session.Options)
+	if err != nil {
+		// Com o vídeo já criado na API, repetir geraria um registro duplicado.
+		if errors.Is(err, errJobNotQueued) {
+			deleteUploadSession(c, redisClient, session.ID)
+		}
+		respondRegisterError(c, videoID, err)
+		return
+	}
+
+	deleteUploadSession(c, redisClient, session.ID)
+
+	c.JSON(http.StatusCreated, UploadResponse{
+		Success: true,
+		Message: "Vídeo enviado com sucesso e enviado para processamento!",
+		VideoID: videoID,
+		URL:     session.URL,
+	})
+}
+
+func deleteUploadSession(c *gin.Context, redisClient SessionStore, uploadID string) {
+	if err := redisClient.DeleteUploadSession(c.Request.Context(), uploadID); err != nil {
+		log.Printf("Erro ao remover sessão de upload %s: %v", uploadID, err)
+	}
+}
+
+func loadUploadSession(c *gin.Context, redisClient SessionStore) (*cache.UploadSession, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return nil, false
+	}
+
+	session, err := redisClient.GetUploadSession(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ResumableUploadResponse{
+			Success: false,
+			Message: "Erro ao buscar sessão de upload: " + err.Error(),
+		})
+		return nil, false
+	}
+
+	if session == nil || session.UserID != userID {
+		c.JSON(http.StatusNotFound, ResumableUploadResponse{
+			Success: false,
+			Message: "Sessão de upload não encontrada",
+		})
+		return nil, false
+	}
+
+	return session, true
+}
+
+func getUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, UploadResponse{
+			Success: false,
+			Message: "Usuário não autenticado",
+		})
+		return 0, false
+	}
+	return uint(userID.(int)), true
+}
+
+// RunStaleUploadCleanup aborta periodicamente, até ctx ser cancelado, os
+// uploads multipart cujas sessões expiraram, liberando as partes já
+// armazenadas no MinIO.
+func RunStaleUploadCleanup(ctx context.Context, minioClient StaleUploadStore) {
+	ticker := time.NewTicker(staleUploadScanInterval)
+	defer ticker.Stop()
+
+	for {
+		if aborted := abortStaleUploads(ctx, minioClient, time.Now()); aborted > 0 {
+			log.Printf("🧹 %d uploads multipart abandonados abortados", aborted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// abortStaleUploads aborta os uploads multipart sem atividade há mais de
+// staleUploadAge em now e devolve quantos foram abortados.
+func abortStaleUploads(ctx context.Context, minioClient StaleUploadStore, now time.Time) int {
+	uploads, err := minioClient.ListMultipartUploads(ctx)
+	if err != nil {
+		log.Printf("Erro ao listar uploads multipart: %v", err)
+		return 0
+	}
+
+	aborted := 0
+	for _, upload := range uploads {
+		if now.Sub(upload.LastActivity) < staleUploadAge {
+			continue
+		}
+		if err := minioClient.AbortMultipartUpload(ctx, upload.ObjectName, upload.UploadID); err != nil {
+			log.Printf("Erro ao abortar upload multipart abandonado %s: %v", upload.ObjectName, err)
+			continue
+		}
+		aborted++
+	}
+	return aborted
+}
+
+func generateUploadID() string {
+	return "upload_" + randomToken()
+}
+
+// generateLockToken identifica a requisição dona do lock de um upload.
+func generateLockToken() string {
+	return randomToken()
+}
+
+// randomToken gera um identificador aleatório, imprevisível e sem risco de
+// colisão entre réplicas.
+func randomToken() string {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(token)
+}
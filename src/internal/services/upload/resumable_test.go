@@ -0,0 +1,278 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"src/internal/cache"
+	"src/internal/models"
+	"src/internal/storage"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeMultipartStore struct {
+	parts       map[int][]byte
+	completeErr error
+	completes   int
+}
+
+func (f *fakeMultipartStore) NewMultipartUpload(ctx context.Context, objectName string) (string, error) {
+	return "multipart_1", nil
+}
+
+func (f *fakeMultipartStore) UploadPart(ctx context.Context, objectName, uploadID string, partNumber int, data io.Reader, size int64) (*storage.UploadedPart, error) {
+	body, err := io.ReadAll(data)
+	if err != nil {
+		return nil, err
+	}
+	f.parts[partNumber] = body
+	return &storage.UploadedPart{PartNumber: partNumber, ETag: "etag-" + strconv.Itoa(partNumber)}, nil
+}
+
+func (f *fakeMultipartStore) CompleteMultipartUpload(ctx context.Context, objectName, uploadID string, parts []storage.UploadedPart) (string, error) {
+	f.completes++
+	if f.completeErr != nil {
+		err := f.completeErr
+		f.completeErr = nil
+		return "", err
+	}
+	return "http://minio:9000/videos/" + objectName, nil
+}
+
+func (f *fakeMultipartStore) AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error {
+	return nil
+}
+
+type fakeSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]cache.UploadSession
+	locks    map[string]string
+	statuses []cache.ProcessingStatus
+}
+
+func newFakeSessionStore() *fakeSessionStore {
+	return &fakeSessionStore{
+		sessions: make(map[string]cache.UploadSession),
+		locks:    make(map[string]string),
+	}
+}
+
+func (f *fakeSessionStore) SetProcessingStatus(ctx context.Context, status *cache.ProcessingStatus) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statuses = append(f.statuses, *status)
+	return nil
+}
+
+func (f *fakeSessionStore) GetUploadSession(ctx context.Context, uploadID string) (*cache.UploadSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[uploadID]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (f *fakeSessionStore) SetUploadSession(ctx context.Context, session *cache.UploadSession) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[session.ID] = *session
+	return nil
+}
+
+func (f *fakeSessionStore) DeleteUploadSession(ctx context.Context, uploadID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.sessions, uploadID)
+	return nil
+}
+
+func (f *fakeSessionStore) AcquireUploadLock(ctx context.Context, uploadID, token string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, locked := f.locks[uploadID]; locked {
+		return false, nil
+	}
+	f.locks[uploadID] = token
+	return true, nil
+}
+
+// ReleaseUploadLock se comporta como o Redis: falha com o contexto
+// cancelado e só remove o lock do próprio dono.
+func (f *fakeSessionStore) ReleaseUploadLock(ctx context.Context, uploadID, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.locks[uploadID] == token {
+		delete(f.locks, uploadID)
+	}
+	return nil
+}
+
+type fakeJobQueue struct {
+	jobs []models.VideoProcessingJob
+}
+
+func (f *fakeJobQueue) Enqueue(ctx context.Context, job *models.VideoProcessingJob) error {
+	f.jobs = append(f.jobs, *job)
+	return nil
+}
+
+// fakeVideoAPI aceita a criação de vídeos na API principal com o ID 42.
+type fakeVideoAPI struct {
+	created int
+}
+
+func (f *fakeVideoAPI) CreateVideo(title, url string, userID uint, authToken string) (uint, error) {
+	f.created++
+	return 42, nil
+}
+
+func (f *fakeVideoAPI) UpdateVideoStatus(videoID uint, status string, authToken string) error {
+	return nil
+}
+
+func (f *fakeVideoAPI) UpdateVideoOutput(videoID uint, outputKey string, authToken string) error {
+	return nil
+}
+
+func sendChunk(sessions *fakeSessionStore, store *fakeMultipartStore, jobs *fakeJobQueue, uploadID string, offset int64, body []byte) *httptest.ResponseRecorder {
+	return sendChunkTo(context.Background(), sessions, store, &fakeVideoAPI{}, jobs, uploadID, offset, body)
+}
+
+func sendChunkTo(ctx context.Context, sessions *fakeSessionStore, store *fakeMultipartStore, videoAPI *fakeVideoAPI, jobs *fakeJobQueue, uploadID string, offset int64, body []byte) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	c.Request = httptest.NewRequestWithContext(ctx, http.MethodPatch, "/upload/video/resumable/"+uploadID, bytes.NewReader(body))
+	c.Request.Header.Set(UploadOffsetHeader, strconv.FormatInt(offset, 10))
+	c.Params = gin.Params{{Key: "id", Value: uploadID}}
+	c.Set("userID", 1)
+
+	HandleResumableChunk(c, store, sessions, videoAPI, jobs)
+	return recorder
+}
+
+func TestResumableChunkRetriesFailedFinalize(t *testing.T) {
+	sessions := newFakeSessionStore()
+	store := &fakeMultipartStore{parts: make(map[int][]byte), completeErr: errors.New("minio indisponível")}
+	videoAPI := &fakeVideoAPI{}
+	jobs := &fakeJobQueue{}
+
+	content := []byte("conteúdo do vídeo")
+	sessions.sessions["upload_1"] = cache.UploadSession{
+		ID:         "upload_1",
+		UserID:     1,
+		FileName:   "video.mp4",
+		ObjectName: "1/input/video.mp4",
+		UploadID:   "multipart_1",
+		Size:       int64(len(content)),
+	}
+
+	recorder := sendChunkTo(context.Background(), sessions, store, videoAPI, jobs, "upload_1", 0, content)
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, esperado %d quando a conclusão falha", recorder.Code, http.StatusInternalServerError)
+	}
+	session, ok := sessions.sessions["upload_1"]
+	if !ok || session.Offset != session.Size || len(session.Parts) != 1 {
+		t.Fatalf("sessão deveria continuar com todas as partes após a falha: %+v", session)
+	}
+	if len(sessions.locks) != 0 {
+		t.Error("o lock do upload deveria ser liberado após a falha")
+	}
+
+	recorder = sendChunkTo(context.Background(), sessions, store, videoAPI, jobs, "upload_1", session.Size, nil)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("status = %d, esperado %d ao repetir a conclusão: %s", recorder.Code, http.StatusCreated, recorder.Body.String())
+	}
+	if store.completes != 2 || len(store.parts) != 1 {
+		t.Errorf("conclusões = %d, partes = %d, esperado 2 conclusões e a parte enviada uma vez", store.completes, len(store.parts))
+	}
+	if videoAPI.created != 1 || len(jobs.jobs) != 1 || jobs.jobs[0].VideoID != 42 {
+		t.Errorf("vídeos criados = %d, jobs enfileirados = %v, esperado um vídeo e um job do vídeo 42", videoAPI.created, jobs.jobs)
+	}
+	if _, ok := sessions.sessions["upload_1"]; ok {
+		t.Error("sessão deveria ser removida após a conclusão")
+	}
+}
+
+func TestResumableChunkRejectsEmptyBodyBeforeEnd(t *testing.T) {
+	sessions := newFakeSessionStore()
+	sessions.sessions["upload_1"] = cache.UploadSession{ID: "upload_1", UserID: 1, Size: 100}
+
+	recorder := sendChunk(sessions, &fakeMultipartStore{parts: make(map[int][]byte)}, &fakeJobQueue{}, "upload_1", 0, nil)
+	if recorder.Code != http.StatusLengthRequired {
+		t.Errorf("status = %d, esperado %d", recorder.Code, http.StatusLengthRequired)
+	}
+}
+
+func TestResumableChunkReleasesLockAfterClientDisconnects(t *testing.T) {
+	sessions := newFakeSessionStore()
+	sessions.sessions["upload_1"] = cache.UploadSession{ID: "upload_1", UserID: 1, ObjectName: "1/input/video.mp4", Size: 2 * MinChunkSize}
+	store := &fakeMultipartStore{parts: make(map[int][]byte)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sendChunkTo(ctx, sessions, store, &fakeVideoAPI{}, &fakeJobQueue{}, "upload_1", 0, make([]byte, MinChunkSize))
+
+	if len(sessions.locks) != 0 {
+		t.Error("o lock deveria ser liberado mesmo com o cliente desconectado")
+	}
+}
+
+type fakeStaleUploadStore struct {
+	uploads []storage.MultipartUpload
+	aborted []string
+}
+
+func (f *fakeStaleUploadStore) ListMultipartUploads(ctx context.Context) ([]storage.MultipartUpload, error) {
+	return f.uploads, nil
+}
+
+func (f *fakeStaleUploadStore) AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error {
+	f.aborted = append(f.aborted, uploadID)
+	return nil
+}
+
+func TestAbortStaleUploadsKeepsActiveSessions(t *testing.T) {
+	now := time.Now()
+	store := &fakeStaleUploadStore{uploads: []storage.MultipartUpload{
+		{ObjectName: "1/input/antigo.mp4", UploadID: "abandonado", LastActivity: now.Add(-staleUploadAge - time.Minute)},
+		{ObjectName: "1/input/recente.mp4", UploadID: "ativo", LastActivity: now.Add(-time.Hour)},
+	}}
+
+	if aborted := abortStaleUploads(context.Background(), store, now); aborted != 1 {
+		t.Errorf("uploads abortados = %d, esperado 1", aborted)
+	}
+	if len(store.aborted) != 1 || store.aborted[0] != "abandonado" {
+		t.Errorf("uploads abortados = %v, esperado apenas o abandonado", store.aborted)
+	}
+}
+
+func TestGenerateUploadIDIsRandom(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := generateUploadID()
+		if !strings.HasPrefix(id, "upload_") || len(id) != len("upload_")+32 {
+			t.Fatalf("upload ID inesperado: %s", id)
+		}
+		if seen[id] {
+			t.Fatalf("upload ID repetido: %s", id)
+		}
+		seen[id] = true
+	}
+}
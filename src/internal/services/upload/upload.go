@@ -1,23 +1,26 @@
 package upload
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"os"
 	"path/filepath"
+	"src/internal/api"
+	"src/internal/cache"
 	"src/internal/models"
-	"src/internal/queue"
+	"src/internal/services/video_processing"
 	"src/internal/storage"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+var errJobNotQueued = errors.New("job não enfileirado")
+
 type UploadResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
@@ -25,17 +28,17 @@ type UploadResponse struct {
 	URL     string `json:"url,omitempty"`
 }
 
-type VideoCreateRequest struct {
-	Title  string `json:"title"`
-	URL    string `json:"url"`
-	UserID uint   `json:"id_user"`
+// StatusStore grava o status de processamento exibido ao usuário.
+type StatusStore interface {
+	SetProcessingStatus(ctx context.Context, status *cache.ProcessingStatus) error
 }
 
-type VideoCreateResponse struct {
-	ID uint `json:"id"`
+// JobQueue recebe os jobs dos uploads aceitos; implementado por queue.Outbox.
+type JobQueue interface {
+	Enqueue(ctx context.Context, job *models.VideoProcessingJob) error
 }
 
-func HandleVideoUpload(c *gin.Context, minioClient *storage.MinioClient, publisher *queue.Publisher) {
+func HandleVideoUpload(c *gin.Context, minioClient *storage.MinioClient, redisClient StatusStore, videoAPI api.VideoAPI, jobs JobQueue) {
 	file, header, err := c.Request.FormFile("video")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, UploadResponse{
@@ -54,6 +57,15 @@ func HandleVideoUpload(c *gin.Context, minioClient *storage.MinioClient, publish
 		return
 	}
 
+	options, err := parseExtractionOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, UploadResponse{
+			Success: false,
+			Message: "Opções de extração inválidas: " + err.Error(),
+		})
+		return
+	}
+
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, UploadResponse{
@@ -78,99 +90,278 @@ func HandleVideoUpload(c *gin.Context, minioClient *storage.MinioClient, publish
 		return
 	}
 
-	authHeader := c.GetHeader("Authorization")
-	videoID, err := createVideoInAPI(header.Filename, url, userIDUint, authHeader)
+	videoID, err := registerVideo(c, videoAPI, redisClient, jobs, header.Filename, url, userIDUint, options)
 	if err != nil {
-		if deleteErr := minioClient.DeleteFile(c.Request.Context(), objectName); deleteErr != nil {
-			log.Printf("Erro ao deletar arquivo do MinIO: %v", deleteErr)
+		if !errors.Is(err, errJobNotQueued) {
+			if deleteErr := minioClient.DeleteFile(c.Request.Context(), objectName); deleteErr != nil {
+				log.Printf("Erro ao deletar arquivo do MinIO: %v", deleteErr)
+			}
 		}
-		c.JSON(http.StatusInternalServerError, UploadResponse{
-			Success: false,
-			Message: "Erro ao salvar registro na API: " + err.Error(),
-		})
+		respondRegisterError(c, videoID, err)
 		return
 	}
 
+	c.JSON(http.StatusCreated, UploadResponse{
+		Success: true,
+		Message: "Vídeo enviado com sucesso e enviado para processamento!",
+		VideoID: videoID,
+		URL:     url,
+	})
+}
+
+func registerVideo(c *gin.Context, videoAPI api.VideoAPI, redisClient StatusStore, jobs JobQueue, fileName, url string, userID uint, options *models.ExtractionOptions) (uint, error) {
+	authHeader := c.GetHeader("Authorization")
+	videoID, err := videoAPI.CreateVideo(fileName, url, userID, authHeader)
+	if err != nil {
+		return 0, err
+	}
+
 	job := &models.VideoProcessingJob{
 		ID:        generateJobID(),
 		VideoID:   videoID,
-		UserID:    userIDUint,
+		UserID:    userID,
 		VideoURL:  url,
-		FileName:  header.Filename,
+		FileName:  fileName,
 		Status:    models.StatusPending,
+		Options:   options,
 		AuthToken: authHeader,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	if err := publisher.PublishVideoProcessingJob(job); err != nil {
-		fmt.Printf("⚠️ Erro ao enviar job para processamento: %v\n", err)
+	processingStatus := &cache.ProcessingStatus{
+		VideoID:   videoID,
+		UserID:    userID,
+		JobID:     job.ID,
+		Status:    models.StatusPending,
+		Message:   "Aguardando processamento",
+		UpdatedAt: time.Now(),
+	}
+	if err := redisClient.SetProcessingStatus(c.Request.Context(), processingStatus); err != nil {
+		log.Printf("Erro ao salvar status de processamento no cache: %v", err)
 	}
 
-	c.JSON(http.StatusCreated, UploadResponse{
-		Success: true,
-		Message: "Vídeo enviado com sucesso e enviado para processamento!",
-		VideoID: videoID,
-		URL:     url,
-	})
+	if err := jobs.Enqueue(c.Request.Context(), job); err != nil {
+		log.Printf("❌ Job não enfileirado para VideoID=%d: %v", videoID, err)
+		markVideoFailed(c, videoAPI, redisClient, job, authHeader)
+		return videoID, fmt.Errorf("%w: %v", errJobNotQueued, err)
+	}
+
+	return videoID, nil
 }
 
-func generateJobID() string {
-	return fmt.Sprintf("job_%d", time.Now().UnixNano())
+// markVideoFailed registra como falho o vídeo cujo job não pôde ser
+// enfileirado, para que ele não fique pendente para sempre.
+func markVideoFailed(c *gin.Context, videoAPI api.VideoAPI, redisClient StatusStore, job *models.VideoProcessingJob, authHeader string) {
+	if err := videoAPI.UpdateVideoStatus(job.VideoID, models.StatusFailed, authHeader); err != nil {
+		log.Printf("Erro ao atualizar status para failed: %v", err)
+	}
+
+	processingStatus := &cache.ProcessingStatus{
+		VideoID:   job.VideoID,
+		UserID:    job.UserID,
+		JobID:     job.ID,
+		Status:    models.StatusFailed,
+		Message:   "Não foi possível enviar o vídeo para processamento",
+		UpdatedAt: time.Now(),
+	}
+	if err := redisClient.SetProcessingStatus(c.Request.Context(), processingStatus); err != nil {
+		log.Printf("Erro ao salvar status de processamento no cache: %v", err)
+	}
 }
 
-func isValidVideoFile(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	validExts := []string{".mp4", ".avi", ".mov", ".mkv", ".wmv", ".flv", ".webm"}
+func respondRegisterError(c *gin.Context, videoID uint, err error) {
+	if errors.Is(err, errJobNotQueued) {
+		c.JSON(http.StatusServiceUnavailable, UploadResponse{
+			Success: false,
+			Message: "Vídeo registrado, mas não foi possível enviá-lo para processamento: " + err.Error(),
+			VideoID: videoID,
+		})
+		return
+	}
 
-	for _, validExt := range validExts {
-		if ext == validExt {
-			return true
+	c.JSON(http.StatusInternalServerError, UploadResponse{
+		Success: false,
+		Message: "Erro ao salvar registro na API: " + err.Error(),
+	})
+}
+
+func parseExtractionOptions(c *gin.Context) (*models.ExtractionOptions, error) {
+	var opts models.ExtractionOptions
+	provided := false
+
+	floatFields := map[string]*float64{
+		"fps":             &opts.FPS,
+		"interval":        &opts.Interval,
+		"scene_threshold": &opts.SceneThreshold,
+		"start_time":      &opts.StartTime,
+		"end_time":        &opts.EndTime,
+	}
+	for field, target := range floatFields {
+		value := strings.TrimSpace(c.PostForm(field))
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s deve ser numérico", field)
 		}
+		*target = parsed
+		provided = true
 	}
-	return false
-}
 
-func createVideoInAPI(title, url string, userID uint, authHeader string) (uint, error) {
-	videoData := VideoCreateRequest{
-		Title:  title,
-		URL:    url,
-		UserID: userID,
+	intFields := map[string]*int{
+		"max_frames": &opts.MaxFrames,
+		"quality":    &opts.Quality,
+		"width":      &opts.Width,
+		"height":     &opts.Height,
+	}
+	for field, target := range intFields {
+		value := strings.TrimSpace(c.PostForm(field))
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s deve ser um número inteiro", field)
+		}
+		*target = parsed
+		provided = true
 	}
 
-	jsonData, err := json.Marshal(videoData)
-	if err != nil {
-		return 0, err
+	if mode := strings.TrimSpace(c.PostForm("mode")); mode != "" {
+		opts.Mode = mode
+		provided = true
 	}
 
-	apiBaseURL := os.Getenv("API_BASE_URL")
-	if apiBaseURL == "" {
-		apiBaseURL = "http://localhost:8000"
+	if format := strings.TrimSpace(c.PostForm("format")); format != "" {
+		opts.Format = format
+		provided = true
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/v1/videos", apiBaseURL), bytes.NewBuffer(jsonData))
-	if err != nil {
-		return 0, err
+	if enabled, _ := strconv.ParseBool(c.PostForm("contact_sheet")); enabled {
+		sheet := &models.ContactSheetOptions{}
+		sheetFields := map[string]*int{
+			"contact_sheet_columns":     &sheet.Columns,
+			"contact_sheet_rows":        &sheet.Rows,
+			"contact_sheet_thumb_width": &sheet.ThumbWidth,
+		}
+		for field, target := range sheetFields {
+			value := strings.TrimSpace(c.PostForm(field))
+			if value == "" {
+				continue
+			}
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s deve ser um número inteiro", field)
+			}
+			*target = parsed
+		}
+		sheet.Timestamps, _ = strconv.ParseBool(c.PostForm("contact_sheet_timestamps"))
+		opts.ContactSheet = sheet
+		provided = true
 	}
-	req.Header.Set("Authorization", authHeader)
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, err
+	if formats := strings.TrimSpace(c.PostForm("transcode")); formats != "" {
+		transcode := &models.TranscodeOptions{}
+		for _, format := range strings.Split(formats, ",") {
+			switch strings.ToLower(strings.TrimSpace(format)) {
+			case "hls":
+				transcode.HLS = true
+			case "dash":
+				transcode.DASH = true
+			default:
+				return nil, fmt.Errorf("formato de transcodificação não suportado: %s (use hls ou dash)", format)
+			}
+		}
+		if renditions := strings.TrimSpace(c.PostForm("renditions")); renditions != "" {
+			transcode.Renditions = strings.Split(renditions, ",")
+		}
+		if value := strings.TrimSpace(c.PostForm("segment_duration")); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("segment_duration deve ser um número inteiro")
+			}
+			transcode.SegmentDuration = parsed
+		}
+		opts.Transcode = transcode
+		provided = true
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("API retornou status %d: %s", resp.StatusCode, string(body))
+	if format := strings.TrimSpace(c.PostForm("preview")); format != "" {
+		preview := &models.PreviewOptions{Format: format}
+		previewFields := map[string]*int{
+			"preview_segments": &preview.Segments,
+			"preview_width":    &preview.Width,
+			"preview_fps":      &preview.FPS,
+		}
+		for field, target := range previewFields {
+			value := strings.TrimSpace(c.PostForm(field))
+			if value == "" {
+				continue
+			}
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s deve ser um número inteiro", field)
+			}
+			*target = parsed
+		}
+		if value := strings.TrimSpace(c.PostForm("preview_segment_duration")); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("preview_segment_duration deve ser numérico")
+			}
+			preview.SegmentDuration = parsed
+		}
+		opts.Preview = preview
+		provided = true
 	}
 
-	var videoResp VideoCreateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&videoResp); err != nil {
-		return 0, err
+	requestsAudio := false
+	if steps := strings.TrimSpace(c.PostForm("steps")); steps != "" {
+		for _, name := range strings.Split(steps, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			opts.Steps = append(opts.Steps, name)
+			if name == video_processing.StepAudio {
+				requestsAudio = true
+			}
+		}
+		provided = true
+	}
+
+	if opts.Mode == models.ModeAudio || requestsAudio {
+		audio := &models.AudioOptions{Format: strings.TrimSpace(c.PostForm("audio_format"))}
+		if value := strings.TrimSpace(c.PostForm("audio_bitrate")); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("audio_bitrate deve ser um número inteiro")
+			}
+			audio.Bitrate = parsed
+		}
+		audio.Waveform, _ = strconv.ParseBool(c.PostForm("audio_waveform"))
+		audio.Peaks, _ = strconv.ParseBool(c.PostForm("audio_peaks"))
+		opts.Audio = audio
 	}
 
-	return videoResp.ID, nil
+	if !provided {
+		return nil, nil
+	}
+
+	return video_processing.NormalizeExtractionOptions(&opts)
+}
+
+func generateJobID() string {
+	return fmt.Sprintf("job_%d", time.Now().UnixNano())
+}
+
+func isValidVideoFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	validExts := []string{".mp4", ".avi", ".mov", ".mkv", ".wmv", ".flv", ".webm"}
+
+	for _, validExt := range validExts {
+		if ext == validExt {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,58 @@
+package upload
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"src/internal/services/video_processing"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func parseForm(t *testing.T, form url.Values) *gin.Context {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/upload/video", strings.NewReader(form.Encode()))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c
+}
+
+// peaksStep é uma etapa registrada só para os testes, cujo nome contém
+// "audio".
+type peaksStep struct{}
+
+func (peaksStep) Name() string                                    { return "audio-peaks" }
+func (peaksStep) Requires() []string                              { return []string{video_processing.StepProbe} }
+func (peaksStep) Run(state *video_processing.PipelineState) error { return nil }
+
+func TestParseExtractionOptionsMatchesAudioStepByName(t *testing.T) {
+	video_processing.RegisterStep("audio-peaks", func() video_processing.Step { return peaksStep{} })
+
+	tests := []struct {
+		name      string
+		steps     string
+		wantAudio bool
+	}{
+		{"com audio", "probe, Audio", true},
+		{"nome contendo audio", "probe,audio-peaks", false},
+		{"sem audio", "probe,extract-frames,zip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := parseForm(t, url.Values{"steps": {tt.steps}, "audio_format": {"mp3"}})
+
+			opts, err := parseExtractionOptions(c)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if (opts.Audio != nil) != tt.wantAudio {
+				t.Errorf("Audio = %+v com steps=%q, esperado opções de áudio = %v", opts.Audio, tt.steps, tt.wantAudio)
+			}
+		})
+	}
+}
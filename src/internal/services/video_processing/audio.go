@@ -0,0 +1,265 @@
+package video_processing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"src/internal/models"
+	"strings"
+)
+
+const (
+	MinAudioBitrate = 32
+	MaxAudioBitrate = 320
+
+	waveformSize       = "1800x280"
+	waveformColor      = "#3b82f6"
+	peaksSampleRate    = 8000
+	peaksCount         = 1000
+	peaksPCMFileName   = "peaks.pcm"
+	peaksBytesPerFrame = 2
+)
+
+var defaultAudioBitrates = map[string]int{
+	models.AudioMP3:  192,
+	models.AudioAAC:  128,
+	models.AudioOpus: 96,
+}
+
+type AudioResult struct {
+	ObjectName string  `json:"object_name"`
+	Format     string  `json:"format"`
+	Bitrate    int     `json:"bitrate,omitempty"`
+	Duration   float64 `json:"duration"`
+	Waveform   string  `json:"waveform,omitempty"`
+	Peaks      string  `json:"peaks,omitempty"`
+}
+
+type AudioPeaks struct {
+	SampleRate     int       `json:"sample_rate"`
+	SamplesPerPeak int       `json:"samples_per_peak"`
+	Duration       float64   `json:"duration"`
+	Length         int       `json:"length"`
+	Data           []float64 `json:"data"`
+}
+
+func normalizeAudioOptions(opts *models.AudioOptions) (*models.AudioOptions, error) {
+	normalized := *opts
+
+	normalized.Format = strings.ToLower(strings.TrimSpace(normalized.Format))
+	if normalized.Format == "" {
+		normalized.Format = models.AudioMP3
+	}
+
+	switch normalized.Format {
+	case models.AudioMP3, models.AudioAAC, models.AudioOpus:
+		if normalized.Bitrate == 0 {
+			normalized.Bitrate = defaultAudioBitrates[normalized.Format]
+		}
+		if normalized.Bitrate < MinAudioBitrate || normalized.Bitrate > MaxAudioBitrate {
+			return nil, fmt.Errorf("bitrate de áudio deve estar entre %d e %d kbps", MinAudioBitrate, MaxAudioBitrate)
+		}
+	case models.AudioWAV:
+		if normalized.Bitrate != 0 {
+			return nil, fmt.Errorf("bitrate não se aplica ao formato wav")
+		}
+	default:
+		return nil, fmt.Errorf("formato de áudio não suportado: %s (use mp3, aac, opus ou wav)", normalized.Format)
+	}
+
+	return &normalized, nil
+}
+
+func audioExtension(format string) string {
+	switch format {
+	case models.AudioAAC:
+		return "m4a"
+	case models.AudioOpus:
+		return "ogg"
+	default:
+		return format
+	}
+}
+
+func audioContentType(format string) string {
+	switch format {
+	case models.AudioAAC:
+		return "audio/mp4"
+	case models.AudioOpus:
+		return "audio/ogg"
+	case models.AudioWAV:
+		return "audio/wav"
+	default:
+		return "audio/mpeg"
+	}
+}
+
+func audioCodecArgs(opts *models.AudioOptions) []string {
+	switch opts.Format {
+	case models.AudioAAC:
+		return []string{"-c:a", "aac", "-b:a", fmt.Sprintf("%dk", opts.Bitrate), "-movflags", "+faststart"}
+	case models.AudioOpus:
+		return []string{"-c:a", "libopus", "-b:a", fmt.Sprintf("%dk", opts.Bitrate)}
+	case models.AudioWAV:
+		return []string{"-c:a", "pcm_s16le"}
+	default:
+		return []string{"-c:a", "libmp3lame", "-b:a", fmt.Sprintf("%dk", opts.Bitrate)}
+	}
+}
+
+// audioInputArgs aplica a janela start_time/end_time e seleciona a
+// primeira trilha de áudio, descartando vídeo e legendas.
+func audioInputArgs(videoPath string, opts *models.ExtractionOptions) []string {
+	var args []string
+	if opts.StartTime > 0 {
+		args = append(args, "-ss", formatSeconds(opts.StartTime))
+	}
+	args = append(args, "-i", videoPath)
+	if opts.EndTime > 0 {
+		args = append(args, "-t", formatSeconds(opts.EndTime-opts.StartTime))
+	}
+	return append(args, "-map", "0:a:0", "-vn", "-sn")
+}
+
+func extractAudio(ctx context.Context, ffmpeg FFmpegRunner, videoPath, outputDir, baseName string, opts *models.ExtractionOptions, duration float64, onProgress ProgressFunc) (string, error) {
+	audioPath := filepath.Join(outputDir, fmt.Sprintf("%s.%s", baseName, audioExtension(opts.Audio.Format)))
+
+	args := audioInputArgs(videoPath, opts)
+	args = append(args, audioCodecArgs(opts.Audio)...)
+	args = append(args, "-y", audioPath)
+
+	if _, err := ffmpeg.RunFFmpeg(ctx, args, duration, onProgress); err != nil {
+		return "", fmt.Errorf("erro ao extrair áudio: %w", err)
+	}
+
+	return audioPath, nil
+}
+
+func generateWaveform(ctx context.Context, ffmpeg FFmpegRunner, videoPath, outputDir, baseName string, opts *models.ExtractionOptions) (string, error) {
+	waveformPath := filepath.Join(outputDir, baseName+"_waveform.png")
+
+	args := audioInputArgs(videoPath, opts)
+	args = append(args,
+		"-filter_complex", fmt.Sprintf("aformat=channel_layouts=mono,showwavespic=s=%s:colors=%s", waveformSize, waveformColor),
+		"-frames:v", "1",
+		"-y", waveformPath,
+	)
+
+	if _, err := ffmpeg.RunFFmpeg(ctx, args, 0, nil); err != nil {
+		return "", fmt.Errorf("erro ao gerar waveform: %w", err)
+	}
+
+	return waveformPath, nil
+}
+
+// generatePeaks decodifica o áudio para PCM mono de 16 bits e grava em JSON
+// pares mínimo/máximo normalizados entre -1 e 1, no formato usado por
+// players de waveform.
+func generatePeaks(ctx context.Context, ffmpeg FFmpegRunner, videoPath, workDir, outputDir, baseName string, opts *models.ExtractionOptions) (string, error) {
+	pcmPath := filepath.Join(workDir, peaksPCMFileName)
+	defer os.Remove(pcmPath)
+
+	args := audioInputArgs(videoPath, opts)
+	args = append(args,
+		"-ac", "1",
+		"-ar", fmt.Sprintf("%d", peaksSampleRate),
+		"-f", "s16le",
+		"-c:a", "pcm_s16le",
+		"-y", pcmPath,
+	)
+
+	if _, err := ffmpeg.RunFFmpeg(ctx, args, 0, nil); err != nil {
+		return "", fmt.Errorf("erro ao decodificar áudio para peaks: %w", err)
+	}
+
+	pcmFile, err := os.Open(pcmPath)
+	if err != nil {
+		return "", fmt.Errorf("erro ao abrir PCM: %w", err)
+	}
+	defer pcmFile.Close()
+
+	fileInfo, err := pcmFile.Stat()
+	if err != nil {
+		return "", fmt.Errorf("erro ao obter informações do PCM: %w", err)
+	}
+
+	peaks, err := computePeaks(pcmFile, int(fileInfo.Size()/peaksBytesPerFrame), peaksCount)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(peaks)
+	if err != nil {
+		return "", fmt.Errorf("erro ao serializar peaks: %w", err)
+	}
+
+	peaksPath := filepath.Join(outputDir, baseName+"_peaks.json")
+	if err := os.WriteFile(peaksPath, data, 0644); err != nil {
+		return "", fmt.Errorf("erro ao salvar peaks: %w", err)
+	}
+
+	return peaksPath, nil
+}
+
+func computePeaks(r io.Reader, totalSamples, buckets int) (*AudioPeaks, error) {
+	if totalSamples == 0 {
+		return nil, fmt.Errorf("a trilha de áudio está vazia")
+	}
+
+	samplesPerPeak := int(math.Ceil(float64(totalSamples) / float64(buckets)))
+	peaks := &AudioPeaks{
+		SampleRate:     peaksSampleRate,
+		SamplesPerPeak: samplesPerPeak,
+		Duration:       math.Round(float64(totalSamples)/peaksSampleRate*1000) / 1000,
+	}
+
+	raw := make([]byte, samplesPerPeak*peaksBytesPerFrame)
+	buffer := make([]int16, samplesPerPeak)
+	for {
+		n, err := readSamples(r, raw, buffer)
+		if n > 0 {
+			minSample, maxSample := buffer[0], buffer[0]
+			for _, sample := range buffer[1:n] {
+				if sample < minSample {
+					minSample = sample
+				}
+				if sample > maxSample {
+					maxSample = sample
+				}
+			}
+			peaks.Data = append(peaks.Data, normalizeSample(minSample), normalizeSample(maxSample))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler PCM: %w", err)
+		}
+	}
+
+	peaks.Length = len(peaks.Data) / 2
+	return peaks, nil
+}
+
+func readSamples(r io.Reader, raw []byte, buffer []int16) (int, error) {
+	n, err := io.ReadFull(r, raw)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	samples := n / peaksBytesPerFrame
+	for i := 0; i < samples; i++ {
+		buffer[i] = int16(binary.LittleEndian.Uint16(raw[i*peaksBytesPerFrame:]))
+	}
+	return samples, err
+}
+
+func normalizeSample(sample int16) float64 {
+	normalized := math.Max(float64(sample)/math.MaxInt16, -1)
+	return math.Round(normalized*10000) / 10000
+}
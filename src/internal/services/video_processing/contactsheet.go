@@ -0,0 +1,176 @@
+package video_processing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"src/internal/models"
+	"strings"
+)
+
+const (
+	defaultAspectRatio   = 16.0 / 9.0
+	minFrameDisplayTime  = 0.04
+	contactSheetDrawText = "drawtext=text='%{pts\\:hms}':x=4:y=h-th-4:fontsize=14:fontcolor=white:box=1:boxcolor=black@0.6:boxborderw=2"
+)
+
+type sheetTile struct {
+	Sheet string
+	X     int
+	Y     int
+	Start float64
+	End   float64
+}
+
+// generateContactSheets agrupa os frames extraídos em imagens de grade
+// Columns x Rows e escreve um track WebVTT que aponta cada intervalo de
+// tempo para a região correspondente do sprite.
+func generateContactSheets(ctx context.Context, ffmpeg FFmpegRunner, framePaths []string, frames []FrameInfo, opts *models.ExtractionOptions, info *models.VideoMetadata, workDir, outputDir, baseName string) ([]string, string, error) {
+	sheetOpts := opts.ContactSheet
+	thumbWidth := sheetOpts.ThumbWidth
+	thumbHeight := thumbnailHeight(thumbWidth, opts, info)
+	perSheet := sheetOpts.Columns * sheetOpts.Rows
+
+	var duration float64
+	if info != nil {
+		duration = info.Duration
+	}
+
+	var sheets []string
+	var tiles []sheetTile
+
+	for start := 0; start < len(framePaths); start += perSheet {
+		end := start + perSheet
+		if end > len(framePaths) {
+			end = len(framePaths)
+		}
+
+		sheetName := fmt.Sprintf("%s_sheet_%03d.jpg", baseName, len(sheets)+1)
+		sheetPath := filepath.Join(outputDir, sheetName)
+		listPath := filepath.Join(workDir, fmt.Sprintf("sheet_%03d.txt", len(sheets)+1))
+
+		if err := writeConcatList(listPath, framePaths[start:end], frames[start:end]); err != nil {
+			return nil, "", err
+		}
+
+		filters := []string{
+			fmt.Sprintf("setpts=PTS+%s/TB", formatSeconds(frames[start].Timestamp)),
+			fmt.Sprintf("scale=%d:%d", thumbWidth, thumbHeight),
+		}
+		if sheetOpts.Timestamps {
+			filters = append(filters, contactSheetDrawText)
+		}
+		filters = append(filters, fmt.Sprintf("tile=%dx%d", sheetOpts.Columns, sheetOpts.Rows))
+
+		_, err := ffmpeg.RunFFmpeg(ctx, []string{
+			"-f", "concat",
+			"-safe", "0",
+			"-i", listPath,
+			"-vf", strings.Join(filters, ","),
+			"-frames:v", "1",
+			"-q:v", "3",
+			"-y", sheetPath,
+		}, 0, nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("erro ao gerar contact sheet %s: %w", sheetName, err)
+		}
+
+		for i := start; i < end; i++ {
+			position := i - start
+			tiles = append(tiles, sheetTile{
+				Sheet: sheetName,
+				X:     (position % sheetOpts.Columns) * thumbWidth,
+				Y:     (position / sheetOpts.Columns) * thumbHeight,
+				Start: frames[i].Timestamp,
+				End:   frameEndTime(frames, i, duration),
+			})
+		}
+
+		sheets = append(sheets, sheetPath)
+	}
+
+	trackPath := filepath.Join(outputDir, baseName+"_thumbnails.vtt")
+	if err := writeThumbnailTrack(trackPath, tiles, thumbWidth, thumbHeight); err != nil {
+		return nil, "", err
+	}
+
+	return sheets, trackPath, nil
+}
+
+// writeConcatList monta a lista do demuxer concat usando a distância entre
+// frames como duração, de modo que o pts de cada imagem reflita o timestamp
+// original no vídeo.
+func writeConcatList(listPath string, framePaths []string, frames []FrameInfo) error {
+	var b strings.Builder
+	b.WriteString("ffconcat version 1.0\n")
+
+	for i, framePath := range framePaths {
+		absPath, err := filepath.Abs(framePath)
+		if err != nil {
+			return fmt.Errorf("erro ao resolver caminho do frame: %w", err)
+		}
+
+		displayTime := 1.0
+		if i+1 < len(frames) {
+			displayTime = math.Max(frames[i+1].Timestamp-frames[i].Timestamp, minFrameDisplayTime)
+		}
+
+		fmt.Fprintf(&b, "file '%s'\nduration %s\n", strings.ReplaceAll(absPath, "'", "'\\''"), formatSeconds(displayTime))
+	}
+
+	return os.WriteFile(listPath, []byte(b.String()), 0644)
+}
+
+func writeThumbnailTrack(trackPath string, tiles []sheetTile, width, height int) error {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+
+	for _, tile := range tiles {
+		fmt.Fprintf(&b, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			formatVTTTime(tile.Start), formatVTTTime(tile.End), tile.Sheet, tile.X, tile.Y, width, height)
+	}
+
+	return os.WriteFile(trackPath, []byte(b.String()), 0644)
+}
+
+func frameEndTime(frames []FrameInfo, index int, duration float64) float64 {
+	if index+1 < len(frames) {
+		return frames[index+1].Timestamp
+	}
+	if duration > frames[index].Timestamp {
+		return duration
+	}
+	return frames[index].Timestamp + 1
+}
+
+func thumbnailHeight(thumbWidth int, opts *models.ExtractionOptions, info *models.VideoMetadata) int {
+	aspect := defaultAspectRatio
+
+	switch {
+	case opts.Width > 0 && opts.Height > 0:
+		aspect = float64(opts.Width) / float64(opts.Height)
+	case info != nil && info.Width > 0 && info.Height > 0:
+		aspect = float64(info.Width) / float64(info.Height)
+		// O ffmpeg aplica a rotação ao decodificar, então os frames saem com os lados trocados.
+		if info.Rotation%180 != 0 {
+			aspect = 1 / aspect
+		}
+	}
+
+	height := int(math.Round(float64(thumbWidth) / aspect))
+	if height%2 != 0 {
+		height++
+	}
+	return height
+}
+
+func formatVTTTime(seconds float64) string {
+	totalMillis := int64(math.Round(seconds * 1000))
+	hours := totalMillis / 3600000
+	minutes := (totalMillis % 3600000) / 60000
+	secs := (totalMillis % 60000) / 1000
+	millis := totalMillis % 1000
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, secs, millis)
+}
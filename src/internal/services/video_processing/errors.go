@@ -0,0 +1,96 @@
+package video_processing
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorClass classifica a falha de um job para decidir se vale tentar de
+// novo.
+type ErrorClass string
+
+const (
+	// Falhas permanentes: uma nova tentativa teria o mesmo resultado.
+	ErrorInvalidInput  ErrorClass = "invalid_input"
+	ErrorDecodeFailure ErrorClass = "decode_failure"
+	ErrorTimeout       ErrorClass = "timeout"
+
+	// Falhas transitórias: dependem de serviços ou recursos que podem voltar.
+	ErrorStorageUnavailable ErrorClass = "storage_unavailable"
+	ErrorAPIUnavailable     ErrorClass = "api_unavailable"
+	ErrorInterrupted        ErrorClass = "interrupted"
+	ErrorInternal           ErrorClass = "internal"
+)
+
+// Retryable indica se uma falha desta classe pode ter outro resultado numa
+// nova tentativa.
+func (c ErrorClass) Retryable() bool {
+	switch c {
+	case ErrorInvalidInput, ErrorDecodeFailure, ErrorTimeout:
+		return false
+	default:
+		return true
+	}
+}
+
+// ProcessingError associa uma classe ao erro original.
+type ProcessingError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func NewProcessingError(class ErrorClass, err error) *ProcessingError {
+	return &ProcessingError{Class: class, Err: err}
+}
+
+func (e *ProcessingError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProcessingError) Unwrap() error {
+	return e.Err
+}
+
+// classify devolve err com a classe informada, a menos que ele já tenha uma.
+func classify(class ErrorClass, err error) error {
+	var processingErr *ProcessingError
+	if err == nil || errors.As(err, &processingErr) {
+		return err
+	}
+	return NewProcessingError(class, err)
+}
+
+// ErrorClassOf devolve a classe de err. A classe atribuída explicitamente
+// tem precedência; cancelamentos sem classe são interrupções e os demais
+// erros sem classe são tratados como internos. Prazos expirados não viram
+// timeout aqui, já que timeouts de clientes HTTP também satisfazem
+// context.DeadlineExceeded: só o processador, que conhece o prazo do job,
+// decide isso (veja failureClass).
+func ErrorClassOf(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	var processingErr *ProcessingError
+	if errors.As(err, &processingErr) {
+		return processingErr.Class
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorInterrupted
+	}
+	return ErrorInternal
+}
+
+// failureClass classifica a falha de um job a partir do estado do contexto
+// do job. O prazo do próprio job expirado é timeout e o cancelamento é
+// interrupção, mesmo que a etapa tenha atribuído outra classe ao ffmpeg ou
+// ao ffprobe interrompidos; nos demais casos vale ErrorClassOf.
+func failureClass(jobCtx context.Context, err error) ErrorClass {
+	switch {
+	case errors.Is(jobCtx.Err(), context.DeadlineExceeded):
+		return ErrorTimeout
+	case jobCtx.Err() != nil:
+		return ErrorInterrupted
+	}
+	return ErrorClassOf(err)
+}
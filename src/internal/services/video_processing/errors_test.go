@@ -0,0 +1,131 @@
+package video_processing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"src/internal/models"
+	"src/internal/storage/storagetest"
+	"testing"
+	"time"
+)
+
+func TestErrorClassOf(t *testing.T) {
+	decodeErr := NewProcessingError(ErrorDecodeFailure, errors.New("exit status 1"))
+	httpTimeout := &url.Error{Op: "Put", URL: "http://api/videos/1/status", Err: context.DeadlineExceeded}
+
+	tests := []struct {
+		name      string
+		err       error
+		want      ErrorClass
+		retryable bool
+	}{
+		{"sem classe", errors.New("disco cheio"), ErrorInternal, true},
+		{"classe preservada ao embrulhar", fmt.Errorf("falha na etapa extract_frames: %w", decodeErr), ErrorDecodeFailure, false},
+		{"storage indisponível", NewProcessingError(ErrorStorageUnavailable, errors.New("connection reset")), ErrorStorageUnavailable, true},
+		{"classe explícita vence o prazo", NewProcessingError(ErrorDecodeFailure, fmt.Errorf("ffmpeg interrompido: %w", context.DeadlineExceeded)), ErrorDecodeFailure, false},
+		{"timeout do cliente HTTP", NewProcessingError(ErrorAPIUnavailable, fmt.Errorf("erro ao atualizar status: %w", httpTimeout)), ErrorAPIUnavailable, true},
+		{"timeout do cliente HTTP sem classe", fmt.Errorf("erro ao atualizar status: %w", httpTimeout), ErrorInternal, true},
+		{"encerramento do serviço", fmt.Errorf("ffmpeg interrompido: %w", context.Canceled), ErrorInterrupted, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorClassOf(tt.err); got != tt.want {
+				t.Errorf("ErrorClassOf = %s, esperado %s", got, tt.want)
+			}
+			if got := ErrorClassOf(tt.err).Retryable(); got != tt.retryable {
+				t.Errorf("Retryable = %v, esperado %v", got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestProcessVideoMissingSourceIsPermanent(t *testing.T) {
+	processor := NewProcessorWithDependencies(storagetest.NewMemoryStore(), nil, t.TempDir())
+
+	result := processor.ProcessVideo(context.Background(), &models.VideoProcessingJob{
+		ID:       "job_test",
+		VideoID:  42,
+		UserID:   1,
+		VideoURL: "http://minio:9000/videos/1/input/inexistente.mp4",
+	}, nil)
+
+	if result.Status != models.StatusFailed || result.ErrorClass != ErrorInvalidInput {
+		t.Errorf("status = %s, classe = %s, esperado falha %s", result.Status, result.ErrorClass, ErrorInvalidInput)
+	}
+}
+
+func TestFailureClassUsesJobDeadline(t *testing.T) {
+	decodeErr := NewProcessingError(ErrorDecodeFailure, fmt.Errorf("ffmpeg interrompido: %w", context.DeadlineExceeded))
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if got := failureClass(expired, decodeErr); got != ErrorTimeout {
+		t.Errorf("com o prazo do job expirado: classe = %s, esperado %s", got, ErrorTimeout)
+	}
+
+	canceled, cancelJob := context.WithCancel(context.Background())
+	cancelJob()
+	if got := failureClass(canceled, decodeErr); got != ErrorInterrupted {
+		t.Errorf("com o job cancelado: classe = %s, esperado %s", got, ErrorInterrupted)
+	}
+
+	httpTimeout := NewProcessingError(ErrorStorageUnavailable, &url.Error{Op: "Get", URL: "http://minio/videos/1", Err: context.DeadlineExceeded})
+	if got := failureClass(context.Background(), httpTimeout); got != ErrorStorageUnavailable {
+		t.Errorf("com o job ativo: classe = %s, esperado %s", got, ErrorStorageUnavailable)
+	}
+}
+
+func TestFFmpegFailureClass(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+	killedErr := exec.Command("sh", "-c", "kill -KILL $$").Run()
+	if exitErr == nil || killedErr == nil {
+		t.Fatal("os comandos de teste deveriam falhar")
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		stderr string
+		want   ErrorClass
+	}{
+		{"arquivo corrompido", exitErr, "video.mp4: Invalid data found when processing input", ErrorDecodeFailure},
+		{"mp4 truncado", exitErr, "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x1] moov atom not found", ErrorDecodeFailure},
+		{"disco cheio", exitErr, "frame_0001.png: No space left on device", ErrorInternal},
+		{"morto por sinal", killedErr, "", ErrorInternal},
+		{"erro desconhecido", exitErr, "Conversion failed!", ErrorInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ffmpegFailureClass(tt.err, tt.stderr); got != tt.want {
+				t.Errorf("ffmpegFailureClass = %s, esperado %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeVideoClassifiesExecFailures(t *testing.T) {
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+
+	_, err := probeVideo(context.Background(), "video.mp4")
+	if class := ErrorClassOf(err); class != ErrorInternal {
+		t.Errorf("sem ffprobe no PATH: classe = %s, esperado %s (%v)", class, ErrorInternal, err)
+	}
+
+	script := "#!/bin/sh\necho 'moov atom not found' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(bin, "ffprobe"), []byte(script), 0755); err != nil {
+		t.Fatalf("erro ao criar ffprobe falso: %v", err)
+	}
+
+	_, err = probeVideo(context.Background(), "video.mp4")
+	if class := ErrorClassOf(err); class != ErrorDecodeFailure {
+		t.Errorf("ffprobe saindo com erro: classe = %s, esperado %s (%v)", class, ErrorDecodeFailure, err)
+	}
+}
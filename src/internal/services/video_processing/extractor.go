@@ -0,0 +1,55 @@
+package video_processing
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"src/internal/models"
+)
+
+// FFmpegRunner executa o ffmpeg com os argumentos informados e devolve o
+// stderr. duration é a duração esperada da saída em segundos, usada para
+// calcular o progresso reportado em onProgress.
+type FFmpegRunner interface {
+	RunFFmpeg(ctx context.Context, args []string, duration float64, onProgress ProgressFunc) (string, error)
+}
+
+// FrameExtractor isola todas as chamadas ao ffprobe/ffmpeg do pipeline,
+// permitindo substituí-las nos testes: além da análise do vídeo e da
+// extração dos frames, as demais etapas executam o ffmpeg por RunFFmpeg.
+type FrameExtractor interface {
+	FFmpegRunner
+	Probe(ctx context.Context, videoPath string) (*models.VideoMetadata, error)
+	// ExtractFrames grava os frames em outputDir e devolve os caminhos e os
+	// timestamps (em segundos no vídeo original) de cada um.
+	ExtractFrames(ctx context.Context, videoPath, outputDir string, opts *models.ExtractionOptions, duration float64, onProgress ProgressFunc) ([]string, []float64, error)
+}
+
+type FFmpegExtractor struct{}
+
+func NewFFmpegExtractor() *FFmpegExtractor {
+	return &FFmpegExtractor{}
+}
+
+func (e *FFmpegExtractor) Probe(ctx context.Context, videoPath string) (*models.VideoMetadata, error) {
+	return probeVideo(ctx, videoPath)
+}
+
+func (e *FFmpegExtractor) RunFFmpeg(ctx context.Context, args []string, duration float64, onProgress ProgressFunc) (string, error) {
+	return runFFmpeg(ctx, args, duration, onProgress)
+}
+
+func (e *FFmpegExtractor) ExtractFrames(ctx context.Context, videoPath, outputDir string, opts *models.ExtractionOptions, duration float64, onProgress ProgressFunc) ([]string, []float64, error) {
+	args := buildExtractionArgs(videoPath, outputDir, opts)
+	ffmpegLog, err := e.RunFFmpeg(ctx, args, duration, onProgress)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro no ffmpeg: %w", err)
+	}
+
+	frames, err := filepath.Glob(filepath.Join(outputDir, "frame_*."+frameExtension(opts)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao listar frames: %w", err)
+	}
+
+	return frames, parseFrameTimestamps(ffmpegLog, opts.StartTime), nil
+}
@@ -0,0 +1,71 @@
+package video_processing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+type FrameInfo struct {
+	File      string  `json:"file"`
+	Timestamp float64 `json:"timestamp"`
+	Size      int64   `json:"size"`
+	SHA256    string  `json:"sha256"`
+}
+
+var showinfoPattern = regexp.MustCompile(`Parsed_showinfo.*\bn:\s*(\d+).*\bpts_time:\s*(-?[\d.]+)`)
+
+// parseFrameTimestamps extrai, na ordem de saída, o pts_time de cada frame
+// registrado pelo filtro showinfo. offset é somado para compensar o -ss de entrada.
+func parseFrameTimestamps(ffmpegLog string, offset float64) []float64 {
+	var timestamps []float64
+	for _, match := range showinfoPattern.FindAllStringSubmatch(ffmpegLog, -1) {
+		ptsTime, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			continue
+		}
+		timestamps = append(timestamps, ptsTime+offset)
+	}
+	return timestamps
+}
+
+func buildFrameInfos(frames []string, timestamps []float64) ([]FrameInfo, error) {
+	infos := make([]FrameInfo, len(frames))
+	for i, frame := range frames {
+		size, checksum, err := hashFile(frame)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao calcular hash de %s: %w", filepath.Base(frame), err)
+		}
+
+		infos[i] = FrameInfo{
+			File:   filepath.Base(frame),
+			Size:   size,
+			SHA256: checksum,
+		}
+		if i < len(timestamps) {
+			infos[i].Timestamp = timestamps[i]
+		}
+	}
+	return infos, nil
+}
+
+func hashFile(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
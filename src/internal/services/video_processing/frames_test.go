@@ -0,0 +1,38 @@
+package video_processing
+
+import "testing"
+
+const showinfoLog = `[Parsed_showinfo_1 @ 0x55d5c8a0b2c0] config in time_base: 1/30000, frame_rate: 30000/1001
+[Parsed_showinfo_1 @ 0x55d5c8a0b2c0] n:   0 pts:      0 pts_time:0       duration:   1001 fmt:yuv420p
+frame=    1 fps=0.0 q=-0.0 size=N/A time=00:00:00.03 bitrate=N/A speed=N/A
+[Parsed_showinfo_1 @ 0x55d5c8a0b2c0] n:   1 pts: 150150 pts_time:5.005   duration:   1001 fmt:yuv420p
+[Parsed_showinfo_1 @ 0x55d5c8a0b2c0] n:   2 pts:  -1001 pts_time:-0.0333667 duration:   1001 fmt:yuv420p
+`
+
+func TestParseFrameTimestamps(t *testing.T) {
+	tests := []struct {
+		name   string
+		log    string
+		offset float64
+		want   []float64
+	}{
+		{"sem offset", showinfoLog, 0, []float64{0, 5.005, -0.0333667}},
+		{"com offset do start_time", showinfoLog, 10, []float64{10, 15.005, 9.9666333}},
+		{"sem showinfo", "frame=    1 fps=0.0 q=-0.0 size=N/A", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseFrameTimestamps(tt.log, tt.offset)
+			if len(got) != len(tt.want) {
+				t.Fatalf("timestamps = %v, esperado %v", got, tt.want)
+			}
+			for i := range got {
+				if diff := got[i] - tt.want[i]; diff > 1e-9 || diff < -1e-9 {
+					t.Errorf("timestamps = %v, esperado %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
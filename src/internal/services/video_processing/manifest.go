@@ -0,0 +1,59 @@
+package video_processing
+
+import (
+	"src/internal/models"
+	"time"
+)
+
+const ManifestFileName = "manifest.json"
+
+type Manifest struct {
+	SourceFile     string      `json:"source_file"`
+	VideoID        uint        `json:"video_id"`
+	JobID          string      `json:"job_id"`
+	Duration       float64     `json:"duration"`
+	Codec          string      `json:"codec,omitempty"`
+	Width          int         `json:"width,omitempty"`
+	Height         int         `json:"height,omitempty"`
+	Mode           string      `json:"mode"`
+	FPS            float64     `json:"fps,omitempty"`
+	SceneThreshold float64     `json:"scene_threshold,omitempty"`
+	StartTime      float64     `json:"start_time,omitempty"`
+	EndTime        float64     `json:"end_time,omitempty"`
+	Format         string      `json:"format"`
+	FrameCount     int         `json:"frame_count"`
+	GeneratedAt    time.Time   `json:"generated_at"`
+	Frames         []FrameInfo `json:"frames"`
+}
+
+func buildManifest(job *models.VideoProcessingJob, opts *models.ExtractionOptions, info *models.VideoMetadata, frames []FrameInfo) *Manifest {
+	manifest := &Manifest{
+		SourceFile:     job.FileName,
+		VideoID:        job.VideoID,
+		JobID:          job.ID,
+		Mode:           opts.Mode,
+		SceneThreshold: opts.SceneThreshold,
+		StartTime:      opts.StartTime,
+		EndTime:        opts.EndTime,
+		Format:         opts.Format,
+		FrameCount:     len(frames),
+		GeneratedAt:    time.Now(),
+		Frames:         frames,
+	}
+
+	if opts.Mode == models.ModeFPS {
+		manifest.FPS = opts.FPS
+		if opts.Interval > 0 {
+			manifest.FPS = 1 / opts.Interval
+		}
+	}
+
+	if info != nil {
+		manifest.Duration = info.Duration
+		manifest.Codec = info.VideoCodec
+		manifest.Width = info.Width
+		manifest.Height = info.Height
+	}
+
+	return manifest
+}
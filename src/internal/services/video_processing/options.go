@@ -0,0 +1,305 @@
+package video_processing
+
+import (
+	"fmt"
+	"path/filepath"
+	"src/internal/models"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultFPS            = 1.0
+	DefaultSceneThreshold = 0.4
+	MaxFPS                = 60.0
+	MaxInterval           = 3600.0
+	MaxFrameLimit         = 10000
+	MinDimension          = 16
+	MaxDimension          = 7680
+
+	DefaultSheetColumns    = 5
+	DefaultSheetRows       = 5
+	DefaultSheetThumbWidth = 320
+	MaxSheetGrid           = 20
+	MaxSheetThumbWidth     = 1920
+)
+
+func DefaultExtractionOptions() *models.ExtractionOptions {
+	return &models.ExtractionOptions{
+		Mode:   models.ModeFPS,
+		FPS:    DefaultFPS,
+		Format: models.FormatPNG,
+	}
+}
+
+// NormalizeExtractionOptions aplica os valores padrão e valida as opções,
+// devolvendo uma cópia pronta para ser convertida em argumentos do ffmpeg.
+func NormalizeExtractionOptions(opts *models.ExtractionOptions) (*models.ExtractionOptions, error) {
+	if opts == nil {
+		return DefaultExtractionOptions(), nil
+	}
+
+	normalized := *opts
+	normalized.Format = strings.ToLower(strings.TrimSpace(normalized.Format))
+	if normalized.Format == "" {
+		normalized.Format = models.FormatPNG
+	}
+	if normalized.Format == "jpg" {
+		normalized.Format = models.FormatJPEG
+	}
+
+	normalized.Mode = strings.ToLower(strings.TrimSpace(normalized.Mode))
+	if normalized.Mode == "" {
+		normalized.Mode = models.ModeFPS
+	}
+
+	switch normalized.Mode {
+	case models.ModeFPS:
+		if normalized.SceneThreshold != 0 {
+			return nil, fmt.Errorf("scene_threshold só se aplica ao modo scene")
+		}
+		if normalized.FPS != 0 && normalized.Interval != 0 {
+			return nil, fmt.Errorf("informe apenas fps ou interval, não ambos")
+		}
+		if normalized.FPS < 0 || normalized.FPS > MaxFPS {
+			return nil, fmt.Errorf("fps deve estar entre 0 e %.0f", MaxFPS)
+		}
+		if normalized.Interval < 0 || normalized.Interval > MaxInterval {
+			return nil, fmt.Errorf("interval deve estar entre 0 e %.0f segundos", MaxInterval)
+		}
+		if normalized.FPS == 0 && normalized.Interval == 0 {
+			normalized.FPS = DefaultFPS
+		}
+	case models.ModeScene, models.ModeKeyframes:
+		if normalized.FPS != 0 || normalized.Interval != 0 {
+			return nil, fmt.Errorf("fps e interval só se aplicam ao modo fps")
+		}
+		if normalized.Mode == models.ModeKeyframes && normalized.SceneThreshold != 0 {
+			return nil, fmt.Errorf("scene_threshold só se aplica ao modo scene")
+		}
+		if normalized.SceneThreshold < 0 || normalized.SceneThreshold > 1 {
+			return nil, fmt.Errorf("scene_threshold deve estar entre 0 e 1")
+		}
+		if normalized.Mode == models.ModeScene && normalized.SceneThreshold == 0 {
+			normalized.SceneThreshold = DefaultSceneThreshold
+		}
+	case models.ModeAudio:
+		if normalized.FPS != 0 || normalized.Interval != 0 || normalized.SceneThreshold != 0 {
+			return nil, fmt.Errorf("fps, interval e scene_threshold não se aplicam ao modo audio")
+		}
+		if normalized.ContactSheet != nil || normalized.Transcode != nil || normalized.Preview != nil {
+			return nil, fmt.Errorf("contact_sheet, transcode e preview não se aplicam ao modo audio")
+		}
+		if normalized.Audio == nil {
+			normalized.Audio = &models.AudioOptions{}
+		}
+	default:
+		return nil, fmt.Errorf("modo de extração não suportado: %s (use fps, scene, keyframes ou audio)", normalized.Mode)
+	}
+
+	if len(normalized.Steps) > 0 {
+		normalized.Steps = normalizeStepNames(normalized.Steps)
+		if _, err := NewPipeline(normalized.Steps); err != nil {
+			return nil, err
+		}
+		if normalized.Mode == models.ModeAudio && containsStep(normalized.Steps, StepExtractFrames) {
+			return nil, fmt.Errorf("a etapa %s não se aplica ao modo audio", StepExtractFrames)
+		}
+	}
+
+	if normalized.Audio != nil {
+		if normalized.Mode != models.ModeAudio && !containsStep(normalized.Steps, StepAudio) {
+			return nil, fmt.Errorf("opções de áudio só se aplicam ao modo audio ou à etapa audio")
+		}
+		audio, err := normalizeAudioOptions(normalized.Audio)
+		if err != nil {
+			return nil, err
+		}
+		normalized.Audio = audio
+	}
+
+	if normalized.StartTime < 0 || normalized.EndTime < 0 {
+		return nil, fmt.Errorf("start_time e end_time não podem ser negativos")
+	}
+	if normalized.EndTime > 0 && normalized.EndTime <= normalized.StartTime {
+		return nil, fmt.Errorf("end_time deve ser maior que start_time")
+	}
+
+	if normalized.MaxFrames < 0 || normalized.MaxFrames > MaxFrameLimit {
+		return nil, fmt.Errorf("max_frames deve estar entre 0 e %d", MaxFrameLimit)
+	}
+
+	switch normalized.Format {
+	case models.FormatPNG, models.FormatJPEG, models.FormatWebP:
+	default:
+		return nil, fmt.Errorf("formato de saída não suportado: %s (use png, jpeg ou webp)", normalized.Format)
+	}
+	if normalized.Quality < 0 || normalized.Quality > 100 {
+		return nil, fmt.Errorf("quality deve estar entre 1 e 100 (0 usa a qualidade padrão do formato)")
+	}
+
+	if err := validateDimension("width", normalized.Width); err != nil {
+		return nil, err
+	}
+	if err := validateDimension("height", normalized.Height); err != nil {
+		return nil, err
+	}
+
+	if normalized.ContactSheet != nil {
+		sheet, err := normalizeContactSheetOptions(normalized.ContactSheet)
+		if err != nil {
+			return nil, err
+		}
+		normalized.ContactSheet = sheet
+	}
+
+	if normalized.Transcode != nil {
+		transcode, err := normalizeTranscodeOptions(normalized.Transcode)
+		if err != nil {
+			return nil, err
+		}
+		normalized.Transcode = transcode
+	}
+
+	if normalized.Preview != nil {
+		preview, err := normalizePreviewOptions(normalized.Preview)
+		if err != nil {
+			return nil, err
+		}
+		normalized.Preview = preview
+	}
+
+	return &normalized, nil
+}
+
+func normalizeContactSheetOptions(opts *models.ContactSheetOptions) (*models.ContactSheetOptions, error) {
+	normalized := *opts
+	if normalized.Columns == 0 {
+		normalized.Columns = DefaultSheetColumns
+	}
+	if normalized.Rows == 0 {
+		normalized.Rows = DefaultSheetRows
+	}
+	if normalized.ThumbWidth == 0 {
+		normalized.ThumbWidth = DefaultSheetThumbWidth
+	}
+
+	if normalized.Columns < 1 || normalized.Columns > MaxSheetGrid || normalized.Rows < 1 || normalized.Rows > MaxSheetGrid {
+		return nil, fmt.Errorf("a grade do contact sheet deve ter entre 1 e %d colunas e linhas", MaxSheetGrid)
+	}
+	if normalized.ThumbWidth < MinDimension || normalized.ThumbWidth > MaxSheetThumbWidth {
+		return nil, fmt.Errorf("thumb_width deve estar entre %d e %d", MinDimension, MaxSheetThumbWidth)
+	}
+
+	return &normalized, nil
+}
+
+func validateDimension(name string, value int) error {
+	if value == 0 {
+		return nil
+	}
+	if value < MinDimension || value > MaxDimension {
+		return fmt.Errorf("%s deve estar entre %d e %d", name, MinDimension, MaxDimension)
+	}
+	return nil
+}
+
+func frameExtension(opts *models.ExtractionOptions) string {
+	if opts.Format == models.FormatJPEG {
+		return "jpg"
+	}
+	return opts.Format
+}
+
+func buildExtractionArgs(videoPath, outputDir string, opts *models.ExtractionOptions) []string {
+	var args []string
+
+	if opts.StartTime > 0 {
+		args = append(args, "-ss", formatSeconds(opts.StartTime))
+	}
+
+	if opts.Mode == models.ModeKeyframes {
+		// Descarta frames que não são I-frames ainda no decoder, sem decodificar o vídeo inteiro.
+		args = append(args, "-skip_frame", "nokey")
+	}
+
+	args = append(args, "-i", videoPath)
+
+	if opts.EndTime > 0 {
+		args = append(args, "-t", formatSeconds(opts.EndTime-opts.StartTime))
+	}
+
+	filters := []string{samplingFilter(opts)}
+	if opts.Width > 0 || opts.Height > 0 {
+		filters = append(filters, fmt.Sprintf("scale=%d:%d", scaleDimension(opts.Width), scaleDimension(opts.Height)))
+	}
+	// showinfo registra o pts de cada frame emitido, usado para calcular os timestamps.
+	filters = append(filters, "showinfo")
+	args = append(args, "-vf", strings.Join(filters, ","))
+
+	if opts.Mode != models.ModeFPS {
+		args = append(args, "-fps_mode", "vfr")
+	}
+
+	if opts.MaxFrames > 0 {
+		args = append(args, "-frames:v", strconv.Itoa(opts.MaxFrames))
+	}
+
+	switch opts.Format {
+	case models.FormatJPEG:
+		if opts.Quality > 0 {
+			args = append(args, "-q:v", strconv.Itoa(jpegQScale(opts.Quality)))
+		}
+	case models.FormatWebP:
+		args = append(args, "-c:v", "libwebp")
+		if opts.Quality > 0 {
+			args = append(args, "-quality", strconv.Itoa(opts.Quality))
+		}
+	}
+
+	framePattern := filepath.Join(outputDir, "frame_%04d."+frameExtension(opts))
+	return append(args, "-y", framePattern)
+}
+
+func samplingFilter(opts *models.ExtractionOptions) string {
+	switch opts.Mode {
+	case models.ModeScene:
+		return fmt.Sprintf("select='gt(scene,%s)'", formatSeconds(opts.SceneThreshold))
+	case models.ModeKeyframes:
+		return "select='eq(pict_type,I)'"
+	}
+
+	if opts.Interval > 0 {
+		return "fps=1/" + formatSeconds(opts.Interval)
+	}
+	return "fps=" + formatSeconds(opts.FPS)
+}
+
+// extractionDuration devolve quantos segundos do vídeo serão percorridos,
+// usado como base para o cálculo de progresso.
+func extractionDuration(opts *models.ExtractionOptions, videoDuration float64) float64 {
+	end := videoDuration
+	if opts.EndTime > 0 && (end == 0 || opts.EndTime < end) {
+		end = opts.EndTime
+	}
+	if end <= opts.StartTime {
+		return 0
+	}
+	return end - opts.StartTime
+}
+
+// jpegQScale converte a qualidade de 1-100 para a escala -q:v do ffmpeg (2 = melhor, 31 = pior).
+func jpegQScale(quality int) int {
+	return 2 + (100-quality)*29/100
+}
+
+func scaleDimension(value int) int {
+	if value == 0 {
+		return -2
+	}
+	return value
+}
+
+func formatSeconds(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
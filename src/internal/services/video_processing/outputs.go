@@ -0,0 +1,68 @@
+package video_processing
+
+import (
+	"fmt"
+	"src/internal/models"
+	"time"
+)
+
+const (
+	OutputZip            = "zip"
+	OutputContactSheet   = "contact_sheet"
+	OutputThumbnailTrack = "thumbnail_track"
+	OutputPoster         = "poster"
+	OutputPreview        = "preview"
+	OutputAudio          = "audio"
+	OutputWaveform       = "waveform"
+	OutputPeaks          = "peaks"
+	OutputHLS            = "hls"
+	OutputDASH           = "dash"
+)
+
+type OutputFile struct {
+	Kind        string `json:"kind"`
+	Path        string `json:"-"`
+	ObjectName  string `json:"object_name"`
+	ContentType string `json:"content_type"`
+}
+
+// OutputBaseName é o prefixo dos arquivos gerados para o job. Inclui o ID do
+// vídeo e do job para que processamentos simultâneos nunca compartilhem nomes.
+func OutputBaseName(job *models.VideoProcessingJob) string {
+	jobID := job.ID
+	if jobID == "" {
+		jobID = fmt.Sprintf("job_%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("video_%d_%s", job.VideoID, jobID)
+}
+
+// primaryOutputKey escolhe a saída principal do processamento, registrada no
+// vídeo pela API: o ZIP de frames, o áudio extraído ou a playlist de streaming.
+func primaryOutputKey(result *ProcessingResult) string {
+	for _, kind := range []string{OutputZip, OutputAudio} {
+		for _, output := range result.Outputs {
+			if output.Kind == kind {
+				return output.ObjectName
+			}
+		}
+	}
+
+	switch {
+	case result.HLSPlaylist != "":
+		return result.HLSPlaylist
+	case result.DASHManifest != "":
+		return result.DASHManifest
+	case len(result.Outputs) > 0:
+		return result.Outputs[0].ObjectName
+	default:
+		return ""
+	}
+}
+
+func outputObjectName(job *models.VideoProcessingJob, name string) string {
+	return fmt.Sprintf("%d/outputs/%s", job.UserID, name)
+}
+
+func thumbnailObjectName(job *models.VideoProcessingJob, name string) string {
+	return fmt.Sprintf("%d/thumbnails/%s", job.UserID, name)
+}
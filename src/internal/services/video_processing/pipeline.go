@@ -0,0 +1,254 @@
+package video_processing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"src/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	StepProbe         = "probe"
+	StepExtractFrames = "extract-frames"
+	StepZip           = "zip"
+	StepThumbnail     = "thumbnail"
+	StepPreview       = "preview"
+	StepContactSheet  = "contact-sheet"
+	StepTranscode     = "transcode"
+	StepAudio         = "audio"
+)
+
+const (
+	StepStatusCompleted = "completed"
+	StepStatusFailed    = "failed"
+	StepStatusSkipped   = "skipped"
+)
+
+// Step é uma etapa do pipeline de processamento. Requires lista as etapas
+// que precisam ter rodado antes dela; as entradas e saídas de cada etapa
+// são lidas e gravadas em PipelineState.
+type Step interface {
+	Name() string
+	Requires() []string
+	Run(state *PipelineState) error
+}
+
+// optionalStep é implementado por etapas cuja falha é registrada no
+// resultado mas não interrompe o pipeline.
+type optionalStep interface {
+	Optional() bool
+}
+
+type PipelineState struct {
+	// Ctx é cancelado no encerramento do serviço ou quando o job excede o
+	// tempo limite; todas as chamadas ao ffmpeg e ao storage o respeitam.
+	Ctx        context.Context
+	Job        *models.VideoProcessingJob
+	Options    *models.ExtractionOptions
+	VideoPath  string
+	WorkDir    string
+	OutputDir  string
+	BaseName   string
+	OnProgress ProgressFunc
+	Extractor  FrameExtractor
+
+	Info       *models.VideoMetadata
+	FramePaths []string
+	Frames     []FrameInfo
+
+	Result ProcessingResult
+
+	parentCtx context.Context
+	started   time.Time
+	cancels   []context.CancelFunc
+}
+
+// setTimeout substitui o prazo do job por timeout contado a partir do início
+// do job, podendo estendê-lo além do prazo inicial do download e do probe.
+func (s *PipelineState) setTimeout(timeout time.Duration) {
+	ctx, cancel := context.WithDeadline(s.parentCtx, s.started.Add(timeout))
+	s.Ctx = ctx
+	s.cancels = append(s.cancels, cancel)
+}
+
+// extendTimeout adia o prazo atual do job em extra, para etapas cujo custo
+// só é conhecido quando elas começam.
+func (s *PipelineState) extendTimeout(extra time.Duration) {
+	deadline, ok := s.Ctx.Deadline()
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithDeadline(s.parentCtx, deadline.Add(extra))
+	s.Ctx = ctx
+	s.cancels = append(s.cancels, cancel)
+}
+
+func (s *PipelineState) close() {
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+}
+
+func (s *PipelineState) addOutput(output OutputFile) {
+	s.Result.Outputs = append(s.Result.Outputs, output)
+}
+
+type StepResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Outputs    int    `json:"outputs,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+var (
+	stepRegistryMu sync.RWMutex
+	stepRegistry   = map[string]func() Step{
+		StepProbe:         func() Step { return probeStep{} },
+		StepExtractFrames: func() Step { return extractFramesStep{} },
+		StepZip:           func() Step { return zipStep{} },
+		StepThumbnail:     func() Step { return thumbnailStep{} },
+		StepPreview:       func() Step { return previewStep{} },
+		StepContactSheet:  func() Step { return contactSheetStep{} },
+		StepTranscode:     func() Step { return transcodeStep{} },
+		StepAudio:         func() Step { return audioStep{} },
+	}
+)
+
+// RegisterStep disponibiliza uma nova etapa para os jobs pelo nome. Pode ser
+// chamada enquanto jobs são processados.
+func RegisterStep(name string, factory func() Step) {
+	stepRegistryMu.Lock()
+	defer stepRegistryMu.Unlock()
+	stepRegistry[name] = factory
+}
+
+func lookupStep(name string) (func() Step, bool) {
+	stepRegistryMu.RLock()
+	defer stepRegistryMu.RUnlock()
+	factory, ok := stepRegistry[name]
+	return factory, ok
+}
+
+type Pipeline struct {
+	steps []Step
+}
+
+func NewPipeline(names []string) (*Pipeline, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("o pipeline precisa de ao menos uma etapa")
+	}
+
+	pipeline := &Pipeline{}
+	seen := make(map[string]bool)
+
+	for _, name := range names {
+		factory, ok := lookupStep(name)
+		if !ok {
+			return nil, fmt.Errorf("etapa desconhecida: %s", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("etapa repetida: %s", name)
+		}
+
+		step := factory()
+		for _, required := range step.Requires() {
+			if !seen[required] {
+				return nil, fmt.Errorf("a etapa %s exige %s antes dela", name, required)
+			}
+		}
+
+		seen[name] = true
+		pipeline.steps = append(pipeline.steps, step)
+	}
+
+	return pipeline, nil
+}
+
+// DefaultSteps monta a lista de etapas equivalente às opções informadas,
+// usada quando o job não declara as próprias etapas.
+func DefaultSteps(opts *models.ExtractionOptions) []string {
+	if opts.Mode == models.ModeAudio {
+		return []string{StepProbe, StepAudio}
+	}
+
+	steps := []string{StepProbe, StepExtractFrames, StepZip, StepThumbnail}
+	if opts.Preview != nil {
+		steps = append(steps, StepPreview)
+	}
+	if opts.ContactSheet != nil {
+		steps = append(steps, StepContactSheet)
+	}
+	if opts.Transcode != nil {
+		steps = append(steps, StepTranscode)
+	}
+	return steps
+}
+
+func normalizeStepNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized
+}
+
+func containsStep(names []string, step string) bool {
+	for _, name := range names {
+		if name == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Run executa as etapas em ordem. Na primeira falha de uma etapa
+// obrigatória as seguintes são marcadas como skipped e o erro é devolvido.
+func (p *Pipeline) Run(state *PipelineState) ([]StepResult, error) {
+	results := make([]StepResult, 0, len(p.steps))
+	var pipelineErr error
+
+	for _, step := range p.steps {
+		if pipelineErr == nil && state.Ctx.Err() != nil {
+			pipelineErr = fmt.Errorf("processamento interrompido antes da etapa %s: %w", step.Name(), state.Ctx.Err())
+		}
+		if pipelineErr != nil {
+			results = append(results, StepResult{Name: step.Name(), Status: StepStatusSkipped})
+			continue
+		}
+
+		outputsBefore := len(state.Result.Outputs)
+		startedAt := time.Now()
+		err := step.Run(state)
+
+		result := StepResult{
+			Name:       step.Name(),
+			Status:     StepStatusCompleted,
+			DurationMs: time.Since(startedAt).Milliseconds(),
+			Outputs:    len(state.Result.Outputs) - outputsBefore,
+		}
+
+		if err != nil {
+			result.Status = StepStatusFailed
+			result.Error = err.Error()
+
+			if optional, ok := step.(optionalStep); ok && optional.Optional() {
+				log.Printf("⚠️ Etapa %s falhou (opcional): %v", step.Name(), err)
+			} else {
+				log.Printf("❌ Etapa %s falhou: %v", step.Name(), err)
+				pipelineErr = fmt.Errorf("falha na etapa %s: %w", step.Name(), err)
+			}
+		} else {
+			log.Printf("⏱️ Etapa %s concluída em %dms", step.Name(), result.DurationMs)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, pipelineErr
+}
@@ -0,0 +1,34 @@
+package video_processing
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"src/internal/models"
+)
+
+const (
+	posterPosition   = 0.10
+	posterCandidates = 60
+	posterMaxWidth   = 1280
+)
+
+// generatePoster escolhe, a partir de 10% da duração, o frame mais
+// representativo entre os próximos candidatos usando o filtro thumbnail.
+func generatePoster(ctx context.Context, ffmpeg FFmpegRunner, videoPath, outputDir, baseName string, info *models.VideoMetadata) (string, error) {
+	posterPath := filepath.Join(outputDir, baseName+"_poster.jpg")
+
+	_, err := ffmpeg.RunFFmpeg(ctx, []string{
+		"-ss", formatSeconds(info.Duration * posterPosition),
+		"-i", videoPath,
+		"-vf", fmt.Sprintf("thumbnail=%d,scale='min(%d,iw)':-2", posterCandidates, posterMaxWidth),
+		"-frames:v", "1",
+		"-q:v", "3",
+		"-y", posterPath,
+	}, 0, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return posterPath, nil
+}
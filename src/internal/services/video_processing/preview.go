@@ -0,0 +1,156 @@
+package video_processing
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"src/internal/models"
+	"strings"
+)
+
+const (
+	DefaultPreviewSegments        = 5
+	DefaultPreviewSegmentDuration = 1.0
+	DefaultPreviewWidth           = 320
+	DefaultPreviewFPS             = 10
+	MaxPreviewSegments            = 20
+	MaxPreviewSegmentDuration     = 5.0
+	MaxPreviewWidth               = 1280
+	MaxPreviewFPS                 = 30
+
+	previewPaletteFilter = "split[a][b];[a]palettegen=stats_mode=diff[p];[b][p]paletteuse=dither=bayer:bayer_scale=5"
+)
+
+func normalizePreviewOptions(opts *models.PreviewOptions) (*models.PreviewOptions, error) {
+	normalized := *opts
+
+	normalized.Format = strings.ToLower(strings.TrimSpace(normalized.Format))
+	if normalized.Format == "" {
+		normalized.Format = models.PreviewGIF
+	}
+	switch normalized.Format {
+	case models.PreviewGIF, models.PreviewMP4, models.PreviewWebM:
+	default:
+		return nil, fmt.Errorf("formato de preview não suportado: %s (use gif, mp4 ou webm)", normalized.Format)
+	}
+
+	if normalized.Segments == 0 {
+		normalized.Segments = DefaultPreviewSegments
+	}
+	if normalized.SegmentDuration == 0 {
+		normalized.SegmentDuration = DefaultPreviewSegmentDuration
+	}
+	if normalized.Width == 0 {
+		normalized.Width = DefaultPreviewWidth
+	}
+	if normalized.FPS == 0 {
+		normalized.FPS = DefaultPreviewFPS
+	}
+
+	if normalized.Segments < 1 || normalized.Segments > MaxPreviewSegments {
+		return nil, fmt.Errorf("o preview deve ter entre 1 e %d trechos", MaxPreviewSegments)
+	}
+	if normalized.SegmentDuration < 0.5 || normalized.SegmentDuration > MaxPreviewSegmentDuration {
+		return nil, fmt.Errorf("a duração de cada trecho do preview deve estar entre 0.5 e %.0f segundos", MaxPreviewSegmentDuration)
+	}
+	if normalized.Width < MinDimension || normalized.Width > MaxPreviewWidth {
+		return nil, fmt.Errorf("a largura do preview deve estar entre %d e %d", MinDimension, MaxPreviewWidth)
+	}
+	// libx264 e libvpx codificam em yuv420p, que exige dimensões pares.
+	if normalized.Format != models.PreviewGIF && normalized.Width%2 != 0 {
+		return nil, fmt.Errorf("a largura do preview em %s deve ser par", normalized.Format)
+	}
+	if normalized.FPS < 1 || normalized.FPS > MaxPreviewFPS {
+		return nil, fmt.Errorf("o fps do preview deve estar entre 1 e %d", MaxPreviewFPS)
+	}
+
+	return &normalized, nil
+}
+
+// previewSegmentStarts distribui os trechos uniformemente pelo vídeo, cada
+// um centrado na sua fatia. Vídeos curtos demais geram menos trechos.
+func previewSegmentStarts(duration float64, segments int, segmentDuration float64) []float64 {
+	if duration <= segmentDuration {
+		return []float64{0}
+	}
+
+	if maxSegments := int(duration / segmentDuration); segments > maxSegments {
+		segments = maxSegments
+	}
+
+	slice := duration / float64(segments)
+	starts := make([]float64, segments)
+	for i := range starts {
+		start := slice*float64(i) + (slice-segmentDuration)/2
+		if start < 0 {
+			start = 0
+		}
+		starts[i] = start
+	}
+	return starts
+}
+
+// generatePreview monta um clipe curto e sem áudio a partir de trechos
+// amostrados do vídeo. Cada trecho é lido com -ss antes do -i para evitar
+// decodificar o vídeo inteiro.
+func generatePreview(ctx context.Context, ffmpeg FFmpegRunner, videoPath, outputDir, baseName string, opts *models.PreviewOptions, info *models.VideoMetadata) (string, error) {
+	previewPath := filepath.Join(outputDir, fmt.Sprintf("%s_preview.%s", baseName, opts.Format))
+	starts := previewSegmentStarts(info.Duration, opts.Segments, opts.SegmentDuration)
+
+	var args []string
+	var filters []string
+	var labels strings.Builder
+	for i, start := range starts {
+		args = append(args,
+			"-ss", formatSeconds(start),
+			"-t", formatSeconds(opts.SegmentDuration),
+			"-i", videoPath,
+		)
+		filters = append(filters, fmt.Sprintf("[%d:v]fps=%d,scale=%d:-2,setsar=1[v%d]", i, opts.FPS, opts.Width, i))
+		fmt.Fprintf(&labels, "[v%d]", i)
+	}
+
+	graph := strings.Join(filters, ";") + fmt.Sprintf(";%sconcat=n=%d:v=1:a=0", labels.String(), len(starts))
+	if opts.Format == models.PreviewGIF {
+		graph += "," + previewPaletteFilter
+	}
+	args = append(args, "-filter_complex", graph, "-an")
+
+	switch opts.Format {
+	case models.PreviewGIF:
+		args = append(args, "-loop", "0")
+	case models.PreviewMP4:
+		args = append(args,
+			"-c:v", "libx264",
+			"-preset", "veryfast",
+			"-crf", "28",
+			"-pix_fmt", "yuv420p",
+			"-movflags", "+faststart",
+		)
+	case models.PreviewWebM:
+		args = append(args,
+			"-c:v", "libvpx-vp9",
+			"-b:v", "0",
+			"-crf", "40",
+			"-row-mt", "1",
+		)
+	}
+	args = append(args, "-y", previewPath)
+
+	if _, err := ffmpeg.RunFFmpeg(ctx, args, 0, nil); err != nil {
+		return "", fmt.Errorf("erro ao gerar preview: %w", err)
+	}
+
+	return previewPath, nil
+}
+
+func previewContentType(format string) string {
+	switch format {
+	case models.PreviewMP4:
+		return "video/mp4"
+	case models.PreviewWebM:
+		return "video/webm"
+	default:
+		return "image/gif"
+	}
+}
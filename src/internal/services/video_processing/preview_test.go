@@ -0,0 +1,27 @@
+package video_processing
+
+import (
+	"src/internal/models"
+	"testing"
+)
+
+func TestNormalizePreviewOptionsWidth(t *testing.T) {
+	tests := []struct {
+		format  string
+		width   int
+		wantErr bool
+	}{
+		{models.PreviewMP4, 320, false},
+		{models.PreviewMP4, 321, true},
+		{models.PreviewWebM, 321, true},
+		{models.PreviewGIF, 321, false},
+		{models.PreviewMP4, MaxPreviewWidth + 2, true},
+	}
+
+	for _, tt := range tests {
+		_, err := normalizePreviewOptions(&models.PreviewOptions{Format: tt.format, Width: tt.width})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("preview %s com largura %d: erro = %v, esperado erro = %v", tt.format, tt.width, err, tt.wantErr)
+		}
+	}
+}
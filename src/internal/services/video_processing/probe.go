@@ -0,0 +1,172 @@
+package video_processing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"src/internal/models"
+	"strconv"
+	"strings"
+)
+
+type ffprobeStream struct {
+	Index        int    `json:"index"`
+	CodecType    string `json:"codec_type"`
+	CodecName    string `json:"codec_name"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	AvgFrameRate string `json:"avg_frame_rate"`
+	RFrameRate   string `json:"r_frame_rate"`
+	BitRate      string `json:"bit_rate"`
+	Channels     int    `json:"channels"`
+	SampleRate   string `json:"sample_rate"`
+	Disposition  struct {
+		AttachedPic int `json:"attached_pic"`
+	} `json:"disposition"`
+	Tags         map[string]string `json:"tags"`
+	SideDataList []struct {
+		Rotation float64 `json:"rotation"`
+	} `json:"side_data_list"`
+}
+
+type ffprobeOutput struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []ffprobeStream `json:"streams"`
+}
+
+func probeVideo(ctx context.Context, videoPath string) (*models.VideoMetadata, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		videoPath,
+	)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			return nil, fmt.Errorf("ffprobe interrompido: %w", ctx.Err())
+		case !errors.As(err, &exitErr):
+			// ffprobe ausente ou que não pôde ser iniciado: problema do worker, não do arquivo.
+			return nil, NewProcessingError(ErrorInternal, fmt.Errorf("erro ao executar ffprobe: %w", err))
+		case killedBySignal(err):
+			return nil, NewProcessingError(ErrorInternal, fmt.Errorf("ffprobe finalizado por sinal: %w", err))
+		}
+		return nil, NewProcessingError(ErrorDecodeFailure, fmt.Errorf("arquivo ilegível pelo ffprobe (corrompido ou formato desconhecido): %s", strings.TrimSpace(tail(stderr.String(), maxStderrTail))))
+	}
+
+	var probe ffprobeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, NewProcessingError(ErrorDecodeFailure, fmt.Errorf("erro ao interpretar saída do ffprobe: %w", err))
+	}
+
+	metadata, err := parseProbeOutput(&probe)
+	if err != nil {
+		return nil, NewProcessingError(ErrorInvalidInput, err)
+	}
+	return metadata, nil
+}
+
+func parseProbeOutput(probe *ffprobeOutput) (*models.VideoMetadata, error) {
+	metadata := &models.VideoMetadata{
+		Container: probe.Format.FormatName,
+		Bitrate:   parseInt64(probe.Format.BitRate),
+	}
+
+	var videoStream *ffprobeStream
+	for i := range probe.Streams {
+		stream := &probe.Streams[i]
+		switch stream.CodecType {
+		case "video":
+			// Capas embutidas (attached_pic) aparecem como stream de vídeo, mas não são o vídeo.
+			if videoStream == nil && stream.Disposition.AttachedPic == 0 {
+				videoStream = stream
+			}
+		case "audio":
+			metadata.AudioTracks = append(metadata.AudioTracks, models.AudioTrack{
+				Index:      stream.Index,
+				Codec:      stream.CodecName,
+				Channels:   stream.Channels,
+				SampleRate: int(parseInt64(stream.SampleRate)),
+				Bitrate:    parseInt64(stream.BitRate),
+				Language:   stream.Tags["language"],
+			})
+		}
+	}
+
+	if videoStream == nil {
+		return nil, fmt.Errorf("o arquivo não contém uma trilha de vídeo")
+	}
+
+	duration, err := strconv.ParseFloat(probe.Format.Duration, 64)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("não foi possível determinar a duração do vídeo")
+	}
+
+	if videoStream.Width <= 0 || videoStream.Height <= 0 {
+		return nil, fmt.Errorf("resolução inválida na trilha de vídeo (%dx%d)", videoStream.Width, videoStream.Height)
+	}
+
+	metadata.Duration = duration
+	metadata.VideoCodec = videoStream.CodecName
+	metadata.Width = videoStream.Width
+	metadata.Height = videoStream.Height
+	metadata.FrameRate = parseFrameRate(videoStream.AvgFrameRate)
+	if metadata.FrameRate == 0 {
+		metadata.FrameRate = parseFrameRate(videoStream.RFrameRate)
+	}
+	metadata.Rotation = streamRotation(videoStream)
+
+	return metadata, nil
+}
+
+func parseFrameRate(value string) float64 {
+	numerator, denominator, found := strings.Cut(value, "/")
+	if !found {
+		rate, _ := strconv.ParseFloat(value, 64)
+		return rate
+	}
+
+	num, err := strconv.ParseFloat(numerator, 64)
+	if err != nil {
+		return 0
+	}
+	den, err := strconv.ParseFloat(denominator, 64)
+	if err != nil || den == 0 {
+		return 0
+	}
+
+	return math.Round(num/den*1000) / 1000
+}
+
+func streamRotation(stream *ffprobeStream) int {
+	for _, sideData := range stream.SideDataList {
+		if sideData.Rotation != 0 {
+			return int(sideData.Rotation)
+		}
+	}
+	if rotate, ok := stream.Tags["rotate"]; ok {
+		rotation, _ := strconv.Atoi(rotate)
+		return rotation
+	}
+	return 0
+}
+
+func parseInt64(value string) int64 {
+	parsed, _ := strconv.ParseInt(value, 10, 64)
+	return parsed
+}
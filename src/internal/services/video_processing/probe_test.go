@@ -0,0 +1,99 @@
+package video_processing
+
+import (
+	"encoding/json"
+	"reflect"
+	"src/internal/models"
+	"strings"
+	"testing"
+)
+
+// probeJSON é a saída do ffprobe de um vídeo de celular gravado na vertical,
+// com capa embutida e uma trilha de áudio.
+const probeJSON = `{
+	"streams": [
+		{"index": 0, "codec_type": "video", "codec_name": "mjpeg", "width": 600, "height": 600,
+		 "avg_frame_rate": "0/0", "r_frame_rate": "90000/1", "disposition": {"attached_pic": 1}},
+		{"index": 1, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080,
+		 "avg_frame_rate": "30000/1001", "r_frame_rate": "30/1", "disposition": {"attached_pic": 0},
+		 "side_data_list": [{"rotation": -90}]},
+		{"index": 2, "codec_type": "audio", "codec_name": "aac", "channels": 2, "sample_rate": "48000",
+		 "bit_rate": "128000", "tags": {"language": "por"}}
+	],
+	"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "12.345000", "bit_rate": "8500000"}
+}`
+
+func TestParseProbeOutput(t *testing.T) {
+	var probe ffprobeOutput
+	if err := json.Unmarshal([]byte(probeJSON), &probe); err != nil {
+		t.Fatalf("erro ao ler saída do ffprobe: %v", err)
+	}
+
+	got, err := parseProbeOutput(&probe)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	want := &models.VideoMetadata{
+		Duration:   12.345,
+		Container:  "mov,mp4,m4a,3gp,3g2,mj2",
+		VideoCodec: "h264",
+		Width:      1920,
+		Height:     1080,
+		FrameRate:  29.97,
+		Bitrate:    8500000,
+		Rotation:   -90,
+		AudioTracks: []models.AudioTrack{
+			{Index: 2, Codec: "aac", Channels: 2, SampleRate: 48000, Bitrate: 128000, Language: "por"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("metadados = %+v, esperado %+v", got, want)
+	}
+}
+
+func TestParseProbeOutputRejectsInvalidVideos(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"somente áudio", `{"streams": [{"codec_type": "audio", "codec_name": "mp3"}], "format": {"duration": "3.0"}}`, "trilha de vídeo"},
+		{"somente capa", `{"streams": [{"codec_type": "video", "width": 600, "height": 600, "disposition": {"attached_pic": 1}}], "format": {"duration": "3.0"}}`, "trilha de vídeo"},
+		{"duração desconhecida", `{"streams": [{"codec_type": "video", "width": 640, "height": 360}], "format": {"duration": "N/A"}}`, "duração"},
+		{"resolução zerada", `{"streams": [{"codec_type": "video", "width": 0, "height": 360}], "format": {"duration": "3.0"}}`, "resolução inválida"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var probe ffprobeOutput
+			if err := json.Unmarshal([]byte(tt.json), &probe); err != nil {
+				t.Fatalf("erro ao ler saída do ffprobe: %v", err)
+			}
+
+			_, err := parseProbeOutput(&probe)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("erro = %v, esperado erro contendo %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFrameRate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"30000/1001", 29.97},
+		{"25/1", 25},
+		{"0/0", 0},
+		{"24", 24},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseFrameRate(tt.value); got != tt.want {
+			t.Errorf("parseFrameRate(%q) = %v, esperado %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package processingtest reúne os fakes e fixtures usados pelos testes do
+// processamento de vídeo e do consumer.
+package processingtest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/internal/models"
+	"src/internal/services/video_processing"
+	"src/internal/storage/storagetest"
+	"sync"
+	"testing"
+)
+
+// VideoObject é o objeto de entrada que NewStore grava e NewJob referencia.
+const VideoObject = "1/input/video.mp4"
+
+// FakeExtractor simula o ffmpeg/ffprobe: Probe devolve Info (ou ProbeErr),
+// ExtractFrames grava FrameCount frames (ou devolve ExtractErr) e RunFFmpeg
+// grava um arquivo vazio no caminho de saída, o último argumento.
+type FakeExtractor struct {
+	Info       *models.VideoMetadata
+	ProbeErr   error
+	FrameCount int
+	ExtractErr error
+
+	mu          sync.Mutex
+	ffmpegCalls [][]string
+}
+
+func NewFakeExtractor(frameCount int) *FakeExtractor {
+	return &FakeExtractor{
+		Info: &models.VideoMetadata{
+			Duration:   10,
+			Container:  "mov,mp4,m4a,3gp,3g2,mj2",
+			VideoCodec: "h264",
+			Width:      1280,
+			Height:     720,
+			FrameRate:  30,
+		},
+		FrameCount: frameCount,
+	}
+}
+
+func (f *FakeExtractor) RunFFmpeg(ctx context.Context, args []string, duration float64, onProgress video_processing.ProgressFunc) (string, error) {
+	f.mu.Lock()
+	f.ffmpegCalls = append(f.ffmpegCalls, args)
+	f.mu.Unlock()
+
+	return "", os.WriteFile(args[len(args)-1], nil, 0644)
+}
+
+// FFmpegCalls devolve os argumentos de cada chamada a RunFFmpeg.
+func (f *FakeExtractor) FFmpegCalls() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]string(nil), f.ffmpegCalls...)
+}
+
+func (f *FakeExtractor) Probe(ctx context.Context, videoPath string) (*models.VideoMetadata, error) {
+	if f.ProbeErr != nil {
+		return nil, f.ProbeErr
+	}
+	return f.Info, nil
+}
+
+func (f *FakeExtractor) ExtractFrames(ctx context.Context, videoPath, outputDir string, opts *models.ExtractionOptions, duration float64, onProgress video_processing.ProgressFunc) ([]string, []float64, error) {
+	if f.ExtractErr != nil {
+		return nil, nil, f.ExtractErr
+	}
+
+	extension := opts.Format
+	if extension == models.FormatJPEG {
+		extension = "jpg"
+	}
+
+	var frames []string
+	var timestamps []float64
+	for i := 1; i <= f.FrameCount; i++ {
+		framePath := filepath.Join(outputDir, fmt.Sprintf("frame_%04d.%s", i, extension))
+		if err := os.WriteFile(framePath, []byte(fmt.Sprintf("frame %d", i)), 0644); err != nil {
+			return nil, nil, err
+		}
+		frames = append(frames, framePath)
+		timestamps = append(timestamps, float64(i-1))
+	}
+
+	if onProgress != nil {
+		onProgress(video_processing.Progress{Percent: 100, Frame: f.FrameCount})
+	}
+	return frames, timestamps, nil
+}
+
+// NewJob cria um job do vídeo VideoObject com as etapas probe, extract-frames
+// e zip.
+func NewJob() *models.VideoProcessingJob {
+	return &models.VideoProcessingJob{
+		ID:       "job_test",
+		VideoID:  42,
+		UserID:   1,
+		VideoURL: "http://minio:9000/videos/" + VideoObject,
+		FileName: "video.mp4",
+		Options: &models.ExtractionOptions{
+			Steps: []string{video_processing.StepProbe, video_processing.StepExtractFrames, video_processing.StepZip},
+		},
+	}
+}
+
+// NewStore cria um storage em memória que já contém VideoObject.
+func NewStore() *storagetest.MemoryStore {
+	store := storagetest.NewMemoryStore()
+	store.Put(VideoObject, []byte("fake video"), "video/mp4")
+	return store
+}
+
+// NewProcessor cria um processor sobre NewStore que trabalha num diretório
+// temporário removido ao fim do teste.
+func NewProcessor(t *testing.T, extractor video_processing.FrameExtractor) (*video_processing.Processor, *storagetest.MemoryStore) {
+	t.Helper()
+
+	store := NewStore()
+	return video_processing.NewProcessorWithDependencies(store, extractor, t.TempDir()), store
+}
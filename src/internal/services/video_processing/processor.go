@@ -2,12 +2,14 @@ package video_processing
 
 import (
 	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"src/internal/models"
 	"src/internal/storage"
@@ -15,80 +17,222 @@ import (
 	"time"
 )
 
+const (
+	// O tempo limite do job é um mínimo fixo mais um múltiplo da duração do
+	// vídeo, para que execuções travadas do ffmpeg sejam abortadas. Até o
+	// probe conhecer a duração, o download e o ffprobe têm só o mínimo.
+	minJobTimeout       = 2 * time.Minute
+	jobTimeoutPerSecond = 10 * time.Second
+	maxJobTimeout       = 2 * time.Hour
+)
+
 type ProcessingResult struct {
-	Status      string    `json:"status"`
-	Message     string    `json:"message"`
-	ProcessedAt time.Time `json:"processed_at"`
-	ZipPath     string    `json:"zip_path,omitempty"`
-	FrameCount  int       `json:"frame_count,omitempty"`
-	Images      []string  `json:"images,omitempty"`
+	Status      string       `json:"status"`
+	Message     string       `json:"message"`
+	ErrorClass  ErrorClass   `json:"error_class,omitempty"`
+	ProcessedAt time.Time    `json:"processed_at"`
+	ZipPath     string       `json:"zip_path,omitempty"`
+	FrameCount  int          `json:"frame_count,omitempty"`
+	Images      []string     `json:"images,omitempty"`
+	Frames      []FrameInfo  `json:"frames,omitempty"`
+	Manifest    *Manifest    `json:"manifest,omitempty"`
+	Outputs     []OutputFile `json:"outputs,omitempty"`
+	OutputKey   string       `json:"output_key,omitempty"`
+
+	HLSPlaylist  string `json:"hls_playlist,omitempty"`
+	DASHManifest string `json:"dash_manifest,omitempty"`
+
+	Audio *AudioResult `json:"audio,omitempty"`
+
+	VideoInfo *models.VideoMetadata `json:"video_info,omitempty"`
+	Steps     []StepResult          `json:"steps,omitempty"`
+
+	workDir string
 }
 
 type Processor struct {
-	minioClient *storage.MinioClient
+	store     storage.ObjectStore
+	extractor FrameExtractor
+	workDirs  *workDirs
+
+	// startTimeout é o prazo inicial do job, que cobre o download e o probe.
+	startTimeout time.Duration
 }
 
 func NewProcessor() *Processor {
-	return &Processor{}
+	return NewProcessorWithDependencies(nil, NewFFmpegExtractor(), DefaultWorkRoot())
+}
+
+func NewProcessorWithMinIO(minioClient *storage.MinioClient, workRoot string) *Processor {
+	processor := NewProcessorWithDependencies(nil, NewFFmpegExtractor(), workRoot)
+	if minioClient != nil {
+		processor.store = minioClient
+	}
+	return processor
 }
 
-func NewProcessorWithMinIO(minioClient *storage.MinioClient) *Processor {
+func NewProcessorWithDependencies(store storage.ObjectStore, extractor FrameExtractor, workRoot string) *Processor {
 	return &Processor{
-		minioClient: minioClient,
+		store:        store,
+		extractor:    extractor,
+		workDirs:     newWorkDirs(workRoot),
+		startTimeout: minJobTimeout,
 	}
 }
 
-func (p *Processor) ProcessVideo(job *models.VideoProcessingJob) *ProcessingResult {
+// CleanupOrphanedWorkDirs remove os diretórios de trabalho deixados por
+// jobs interrompidos. Deve ser chamado na inicialização do serviço.
+func (p *Processor) CleanupOrphanedWorkDirs() (int, error) {
+	return p.workDirs.cleanupOrphans()
+}
+
+func (p *Processor) ProcessVideo(ctx context.Context, job *models.VideoProcessingJob, onProgress ProgressFunc) *ProcessingResult {
 	log.Printf("🎬 Iniciando processamento do vídeo: %s", job.FileName)
+	started := time.Now()
+
+	opts, err := NormalizeExtractionOptions(job.Options)
+	if err != nil {
+		return &ProcessingResult{
+			Status:      models.StatusFailed,
+			Message:     "Opções de extração inválidas: " + err.Error(),
+			ErrorClass:  ErrorInvalidInput,
+			ProcessedAt: time.Now(),
+		}
+	}
+
+	stepNames := opts.Steps
+	if len(stepNames) == 0 {
+		stepNames = DefaultSteps(opts)
+	}
+
+	pipeline, err := NewPipeline(stepNames)
+	if err != nil {
+		return &ProcessingResult{
+			Status:      models.StatusFailed,
+			Message:     "Etapas de processamento inválidas: " + err.Error(),
+			ErrorClass:  ErrorInvalidInput,
+			ProcessedAt: time.Now(),
+		}
+	}
 
-	userTempDir := filepath.Join("videos", fmt.Sprintf("%d", job.UserID), "temp")
-	if err := os.MkdirAll(userTempDir, 0755); err != nil {
+	jobDir, err := p.workDirs.create(job)
+	if err != nil {
 		return &ProcessingResult{
 			Status:      models.StatusFailed,
 			Message:     "Erro ao criar diretório temporário: " + err.Error(),
+			ErrorClass:  ErrorInternal,
 			ProcessedAt: time.Now(),
 		}
 	}
 
-	if err := os.MkdirAll("outputs", 0755); err != nil {
+	outputDir := filepath.Join(jobDir, "outputs")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		p.workDirs.release(jobDir)
 		return &ProcessingResult{
 			Status:      models.StatusFailed,
 			Message:     "Erro ao criar diretório de output: " + err.Error(),
+			ErrorClass:  ErrorInternal,
 			ProcessedAt: time.Now(),
 		}
 	}
 
-	timestamp := time.Now().Format("20060102_150405")
+	jobCtx, cancelJob := context.WithDeadline(ctx, started.Add(p.startTimeout))
 
-	videoPath, err := p.downloadVideoFromMinIO(job.VideoURL, timestamp, userTempDir)
+	videoPath, err := p.downloadVideoFromMinIO(jobCtx, job.VideoURL, jobDir)
 	if err != nil {
+		class := failureClass(jobCtx, err)
+		cancelJob()
+		p.workDirs.release(jobDir)
 		return &ProcessingResult{
 			Status:      models.StatusFailed,
 			Message:     "Erro ao baixar vídeo do MinIO: " + err.Error(),
+			ErrorClass:  class,
 			ProcessedAt: time.Now(),
 		}
 	}
-	defer os.Remove(videoPath)
 
-	result := processVideo(videoPath, userTempDir)
+	state := &PipelineState{
+		Ctx:        jobCtx,
+		Job:        job,
+		Options:    opts,
+		VideoPath:  videoPath,
+		WorkDir:    jobDir,
+		OutputDir:  outputDir,
+		BaseName:   OutputBaseName(job),
+		OnProgress: onProgress,
+		Extractor:  p.extractor,
+
+		parentCtx: ctx,
+		started:   started,
+		cancels:   []context.CancelFunc{cancelJob},
+	}
 
-	processingResult := &ProcessingResult{
-		Status:      models.StatusCompleted,
-		Message:     result.Message,
-		ProcessedAt: time.Now(),
-		ZipPath:     result.ZipPath,
-		FrameCount:  result.FrameCount,
-		Images:      result.Images,
+	log.Printf("🧩 Pipeline: %s", strings.Join(stepNames, " → "))
+
+	steps, err := pipeline.Run(state)
+	var class ErrorClass
+	if err != nil {
+		// Classificado antes de state.close, que cancela o contexto do job.
+		class = failureClass(state.Ctx, err)
 	}
+	state.close()
+
+	processingResult := &state.Result
+	processingResult.Steps = steps
+	processingResult.ProcessedAt = time.Now()
 
-	if result.Status == "failed" {
+	if err != nil {
 		processingResult.Status = models.StatusFailed
+		processingResult.Message = "Erro no processamento: " + err.Error()
+		processingResult.ErrorClass = class
+		p.workDirs.release(jobDir)
+	} else {
+		processingResult.Status = models.StatusCompleted
+		processingResult.Message = completionMessage(processingResult)
+		processingResult.OutputKey = primaryOutputKey(processingResult)
+		processingResult.workDir = jobDir
 	}
 
 	log.Printf("✅ Processamento concluído: VideoID=%d, Status=%s", job.VideoID, processingResult.Status)
 	return processingResult
 }
 
+// ReleaseWorkDir remove o diretório do job depois que as saídas de um
+// processamento concluído foram enviadas ao storage.
+func (p *Processor) ReleaseWorkDir(result *ProcessingResult) {
+	if result == nil || result.workDir == "" {
+		return
+	}
+	p.workDirs.release(result.workDir)
+	result.workDir = ""
+}
+
+func processingTimeout(duration float64) time.Duration {
+	timeout := minJobTimeout + time.Duration(duration*float64(jobTimeoutPerSecond))
+	if timeout > maxJobTimeout {
+		return maxJobTimeout
+	}
+	return timeout
+}
+
+// transcodeTimeout é o tempo extra dado ao transcode: o tempo limite de um
+// job para cada rendition de cada formato de saída, de modo que um HLS+DASH
+// com várias renditions de um vídeo longo não esbarre em maxJobTimeout.
+func transcodeTimeout(duration float64, renditions, formats int) time.Duration {
+	return processingTimeout(duration) * time.Duration(renditions*formats)
+}
+
+func completionMessage(result *ProcessingResult) string {
+	switch {
+	case result.FrameCount > 0:
+		return fmt.Sprintf("Processamento concluído! %d frames extraídos.", result.FrameCount)
+	case result.Audio != nil:
+		return fmt.Sprintf("Processamento concluído! Áudio extraído em %s.", result.Audio.Format)
+	default:
+		return "Processamento concluído!"
+	}
+}
+
 func (p *Processor) ProcessVideoWithError(job *models.VideoProcessingJob) *ProcessingResult {
 	log.Printf("🎬 Iniciando processamento do vídeo (com erro): %s", job.FileName)
 
@@ -97,6 +241,7 @@ func (p *Processor) ProcessVideoWithError(job *models.VideoProcessingJob) *Proce
 	result := &ProcessingResult{
 		Status:      models.StatusFailed,
 		Message:     "Erro durante o processamento do vídeo",
+		ErrorClass:  ErrorInternal,
 		ProcessedAt: time.Now(),
 	}
 
@@ -104,74 +249,7 @@ func (p *Processor) ProcessVideoWithError(job *models.VideoProcessingJob) *Proce
 	return result
 }
 
-func processVideo(videoPath, tempDir string) ProcessingResult {
-	fmt.Printf("Iniciando processamento: %s\n", videoPath)
-
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return ProcessingResult{
-			Status:  "failed",
-			Message: "Erro ao criar diretório temporário: " + err.Error(),
-		}
-	}
-	defer os.RemoveAll(tempDir)
-
-	framePattern := filepath.Join(tempDir, "frame_%04d.png")
-
-	cmd := exec.Command("ffmpeg",
-		"-i", videoPath,
-		"-vf", "fps=1",
-		"-y",
-		framePattern,
-	)
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return ProcessingResult{
-			Status:  "failed",
-			Message: fmt.Sprintf("Erro no ffmpeg: %s\nOutput: %s", err.Error(), string(output)),
-		}
-	}
-
-	frames, err := filepath.Glob(filepath.Join(tempDir, "*.png"))
-	if err != nil || len(frames) == 0 {
-		return ProcessingResult{
-			Status:  "failed",
-			Message: "Nenhum frame foi extraído do vídeo",
-		}
-	}
-
-	fmt.Printf("📸 Extraídos %d frames\n", len(frames))
-
-	originalFileName := filepath.Base(videoPath)
-	originalNameWithoutExt := strings.TrimSuffix(originalFileName, filepath.Ext(originalFileName))
-	zipFilename := fmt.Sprintf("%s.zip", originalNameWithoutExt)
-	zipPath := filepath.Join("outputs", zipFilename)
-
-	err = createZipFile(frames, zipPath)
-	if err != nil {
-		return ProcessingResult{
-			Status:  "failed",
-			Message: "Erro ao criar arquivo ZIP: " + err.Error(),
-		}
-	}
-
-	fmt.Printf("✅ ZIP criado: %s\n", zipPath)
-
-	imageNames := make([]string, len(frames))
-	for i, frame := range frames {
-		imageNames[i] = filepath.Base(frame)
-	}
-
-	return ProcessingResult{
-		Status:     "completed",
-		Message:    fmt.Sprintf("Processamento concluído! %d frames extraídos.", len(frames)),
-		ZipPath:    zipFilename,
-		FrameCount: len(frames),
-		Images:     imageNames,
-	}
-}
-
-func createZipFile(files []string, zipPath string) error {
+func createZipFile(files []string, zipPath string, manifest *Manifest) error {
 	zipFile, err := os.Create(zipPath)
 	if err != nil {
 		return err
@@ -181,6 +259,16 @@ func createZipFile(files []string, zipPath string) error {
 	zipWriter := zip.NewWriter(zipFile)
 	defer zipWriter.Close()
 
+	if manifest != nil {
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return fmt.Errorf("erro ao serializar manifest: %w", err)
+		}
+		if err := addBytesToZip(zipWriter, ManifestFileName, data); err != nil {
+			return err
+		}
+	}
+
 	for _, file := range files {
 		err := addFileToZip(zipWriter, file)
 		if err != nil {
@@ -191,6 +279,22 @@ func createZipFile(files []string, zipPath string) error {
 	return nil
 }
 
+func addBytesToZip(zipWriter *zip.Writer, name string, data []byte) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	}
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, bytes.NewReader(data))
+	return err
+}
+
 func addFileToZip(zipWriter *zip.Writer, filename string) error {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -220,22 +324,25 @@ func addFileToZip(zipWriter *zip.Writer, filename string) error {
 	return err
 }
 
-func (p *Processor) downloadVideoFromMinIO(videoURL, timestamp, userTempDir string) (string, error) {
-	if p.minioClient == nil {
-		return "", fmt.Errorf("cliente MinIO não configurado")
+func (p *Processor) downloadVideoFromMinIO(ctx context.Context, videoURL, workDir string) (string, error) {
+	if p.store == nil {
+		return "", NewProcessingError(ErrorInternal, fmt.Errorf("cliente MinIO não configurado"))
 	}
 
 	parts := strings.Split(videoURL, "/")
 	if len(parts) < 4 {
-		return "", fmt.Errorf("URL do MinIO inválida: %s", videoURL)
+		return "", NewProcessingError(ErrorInvalidInput, fmt.Errorf("URL do MinIO inválida: %s", videoURL))
 	}
 	objectName := strings.Join(parts[4:], "/")
 
-	localPath := filepath.Join(userTempDir, fmt.Sprintf("video_%s.mp4", timestamp))
+	localPath := filepath.Join(workDir, "source"+strings.ToLower(filepath.Ext(objectName)))
 
-	err := p.minioClient.DownloadFile(context.Background(), objectName, localPath)
+	err := p.store.DownloadFile(ctx, objectName, localPath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", NewProcessingError(ErrorInvalidInput, fmt.Errorf("vídeo não encontrado no MinIO: %w", err))
+	}
 	if err != nil {
-		return "", fmt.Errorf("erro ao baixar vídeo do MinIO: %w", err)
+		return "", NewProcessingError(ErrorStorageUnavailable, fmt.Errorf("erro ao baixar vídeo do MinIO: %w", err))
 	}
 
 	log.Printf("📥 Vídeo baixado do MinIO: %s -> %s", objectName, localPath)
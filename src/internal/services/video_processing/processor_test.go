@@ -0,0 +1,313 @@
+package video_processing_test
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/internal/models"
+	"src/internal/services/video_processing"
+	"src/internal/services/video_processing/processingtest"
+	"src/internal/storage/storagetest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func stepStatuses(steps []video_processing.StepResult) map[string]string {
+	statuses := make(map[string]string)
+	for _, step := range steps {
+		statuses[step.Name] = step.Status
+	}
+	return statuses
+}
+
+func TestProcessVideoSuccess(t *testing.T) {
+	processor, _ := processingtest.NewProcessor(t, processingtest.NewFakeExtractor(3))
+
+	var progress []video_processing.Progress
+	result := processor.ProcessVideo(context.Background(), processingtest.NewJob(), func(p video_processing.Progress) {
+		progress = append(progress, p)
+	})
+
+	if result.Status != models.StatusCompleted {
+		t.Fatalf("status = %s, esperado %s (%s)", result.Status, models.StatusCompleted, result.Message)
+	}
+	if result.FrameCount != 3 {
+		t.Errorf("FrameCount = %d, esperado 3", result.FrameCount)
+	}
+	if len(progress) == 0 {
+		t.Error("nenhum progresso reportado")
+	}
+	if result.VideoInfo == nil || result.VideoInfo.Width != 1280 {
+		t.Errorf("VideoInfo não preenchido: %+v", result.VideoInfo)
+	}
+
+	for name, status := range stepStatuses(result.Steps) {
+		if status != video_processing.StepStatusCompleted {
+			t.Errorf("etapa %s com status %s", name, status)
+		}
+	}
+
+	if len(result.Outputs) != 1 || result.Outputs[0].Kind != video_processing.OutputZip {
+		t.Fatalf("saídas inesperadas: %+v", result.Outputs)
+	}
+	if result.Outputs[0].ObjectName != "1/outputs/video_42_job_test.zip" {
+		t.Errorf("ObjectName = %s, esperado 1/outputs/video_42_job_test.zip", result.Outputs[0].ObjectName)
+	}
+	if result.OutputKey != result.Outputs[0].ObjectName {
+		t.Errorf("OutputKey = %s, esperado %s", result.OutputKey, result.Outputs[0].ObjectName)
+	}
+
+	reader, err := zip.OpenReader(result.Outputs[0].Path)
+	if err != nil {
+		t.Fatalf("erro ao abrir ZIP: %v", err)
+	}
+	defer reader.Close()
+
+	names := make(map[string]bool)
+	for _, file := range reader.File {
+		names[file.Name] = true
+	}
+	for _, expected := range []string{video_processing.ManifestFileName, "frame_0001.png", "frame_0002.png", "frame_0003.png"} {
+		if !names[expected] {
+			t.Errorf("ZIP não contém %s", expected)
+		}
+	}
+}
+
+// barrierExtractor segura cada extração até que todos os jobs esperados
+// estejam extraindo, garantindo que eles rodem ao mesmo tempo.
+type barrierExtractor struct {
+	*processingtest.FakeExtractor
+	arrived sync.WaitGroup
+}
+
+func (b *barrierExtractor) ExtractFrames(ctx context.Context, videoPath, outputDir string, opts *models.ExtractionOptions, duration float64, onProgress video_processing.ProgressFunc) ([]string, []float64, error) {
+	b.arrived.Done()
+	b.arrived.Wait()
+	return b.FakeExtractor.ExtractFrames(ctx, videoPath, outputDir, opts, duration, onProgress)
+}
+
+func TestProcessVideoConcurrentJobsDoNotCollide(t *testing.T) {
+	const jobs = 4
+
+	extractor := &barrierExtractor{FakeExtractor: processingtest.NewFakeExtractor(2)}
+	extractor.arrived.Add(jobs)
+	processor, _ := processingtest.NewProcessor(t, extractor)
+
+	results := make([]*video_processing.ProcessingResult, jobs)
+	var wg sync.WaitGroup
+	for i := range results {
+		job := processingtest.NewJob()
+		job.ID = fmt.Sprintf("job_test_%d", i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = processor.ProcessVideo(context.Background(), job, nil)
+		}()
+	}
+	wg.Wait()
+
+	objects := make(map[string]bool)
+	dirs := make(map[string]bool)
+	for i, result := range results {
+		if result.Status != models.StatusCompleted {
+			t.Fatalf("job %d: status = %s, esperado %s: %s", i, result.Status, models.StatusCompleted, result.Message)
+		}
+		output := result.Outputs[0]
+		if objects[output.ObjectName] {
+			t.Errorf("jobs diferentes geraram o mesmo objeto: %s", output.ObjectName)
+		}
+		if dirs[filepath.Dir(output.Path)] {
+			t.Errorf("jobs diferentes gravaram no mesmo diretório: %s", filepath.Dir(output.Path))
+		}
+		objects[output.ObjectName] = true
+		dirs[filepath.Dir(output.Path)] = true
+	}
+
+	processor.ReleaseWorkDir(results[0])
+	if _, err := os.Stat(results[0].Outputs[0].Path); !os.IsNotExist(err) {
+		t.Errorf("saída %s deveria ter sido removida junto com o diretório do job", results[0].Outputs[0].Path)
+	}
+	for _, result := range results[1:] {
+		reader, err := zip.OpenReader(result.Outputs[0].Path)
+		if err != nil {
+			t.Errorf("saída de outro job não deveria ser afetada: %v", err)
+			continue
+		}
+		if len(reader.File) != 3 {
+			t.Errorf("ZIP %s com %d arquivos, esperado o manifest e 2 frames", result.Outputs[0].Path, len(reader.File))
+		}
+		reader.Close()
+		processor.ReleaseWorkDir(result)
+	}
+}
+
+func TestProcessVideoFFmpegFailure(t *testing.T) {
+	extractor := processingtest.NewFakeExtractor(0)
+	extractor.ExtractErr = errors.New("erro no ffmpeg: exit status 1")
+	processor, _ := processingtest.NewProcessor(t, extractor)
+
+	result := processor.ProcessVideo(context.Background(), processingtest.NewJob(), nil)
+
+	if result.Status != models.StatusFailed {
+		t.Fatalf("status = %s, esperado %s", result.Status, models.StatusFailed)
+	}
+	if !strings.Contains(result.Message, "exit status 1") {
+		t.Errorf("mensagem não contém o erro do ffmpeg: %s", result.Message)
+	}
+
+	statuses := stepStatuses(result.Steps)
+	if statuses[video_processing.StepProbe] != video_processing.StepStatusCompleted || statuses[video_processing.StepExtractFrames] != video_processing.StepStatusFailed || statuses[video_processing.StepZip] != video_processing.StepStatusSkipped {
+		t.Errorf("status das etapas inesperados: %v", statuses)
+	}
+	if len(result.Outputs) != 0 {
+		t.Errorf("nenhuma saída esperada, obtidas %d", len(result.Outputs))
+	}
+}
+
+func TestProcessVideoNoFrames(t *testing.T) {
+	processor, _ := processingtest.NewProcessor(t, processingtest.NewFakeExtractor(0))
+
+	result := processor.ProcessVideo(context.Background(), processingtest.NewJob(), nil)
+
+	if result.Status != models.StatusFailed {
+		t.Fatalf("status = %s, esperado %s", result.Status, models.StatusFailed)
+	}
+	if !strings.Contains(result.Message, "nenhum frame") {
+		t.Errorf("mensagem inesperada: %s", result.Message)
+	}
+}
+
+func TestProcessVideoInvalidVideo(t *testing.T) {
+	extractor := processingtest.NewFakeExtractor(3)
+	extractor.ProbeErr = errors.New("o arquivo não contém uma trilha de vídeo")
+	processor, _ := processingtest.NewProcessor(t, extractor)
+
+	result := processor.ProcessVideo(context.Background(), processingtest.NewJob(), nil)
+
+	if result.Status != models.StatusFailed {
+		t.Fatalf("status = %s, esperado %s", result.Status, models.StatusFailed)
+	}
+	if !strings.Contains(result.Message, "vídeo inválido") {
+		t.Errorf("mensagem inesperada: %s", result.Message)
+	}
+}
+
+func TestProcessVideoDownloadFailure(t *testing.T) {
+	processor := video_processing.NewProcessorWithDependencies(storagetest.NewMemoryStore(), processingtest.NewFakeExtractor(3), t.TempDir())
+
+	result := processor.ProcessVideo(context.Background(), processingtest.NewJob(), nil)
+
+	if result.Status != models.StatusFailed {
+		t.Fatalf("status = %s, esperado %s", result.Status, models.StatusFailed)
+	}
+	if !strings.Contains(result.Message, "Erro ao baixar vídeo") {
+		t.Errorf("mensagem inesperada: %s", result.Message)
+	}
+}
+
+func TestProcessVideoRunsStepsThroughExtractor(t *testing.T) {
+	extractor := processingtest.NewFakeExtractor(3)
+	processor, _ := processingtest.NewProcessor(t, extractor)
+
+	job := processingtest.NewJob()
+	job.Options.Steps = []string{video_processing.StepProbe, video_processing.StepThumbnail, video_processing.StepPreview}
+
+	result := processor.ProcessVideo(context.Background(), job, nil)
+	defer processor.ReleaseWorkDir(result)
+
+	if result.Status != models.StatusCompleted {
+		t.Fatalf("status = %s, esperado %s: %s", result.Status, models.StatusCompleted, result.Message)
+	}
+	if len(extractor.FFmpegCalls()) != 2 {
+		t.Errorf("chamadas ao ffmpeg = %d, esperado 2 (poster e preview)", len(extractor.FFmpegCalls()))
+	}
+
+	kinds := make(map[string]bool)
+	for _, output := range result.Outputs {
+		kinds[output.Kind] = true
+	}
+	if !kinds[video_processing.OutputPoster] || !kinds[video_processing.OutputPreview] {
+		t.Errorf("saídas = %v, esperado poster e preview", result.Outputs)
+	}
+}
+
+func TestProcessVideoCanceledContext(t *testing.T) {
+	processor, _ := processingtest.NewProcessor(t, processingtest.NewFakeExtractor(3))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := processor.ProcessVideo(ctx, processingtest.NewJob(), nil)
+
+	if result.Status != models.StatusFailed {
+		t.Fatalf("status = %s, esperado %s", result.Status, models.StatusFailed)
+	}
+	if !strings.Contains(result.Message, "interrompido") {
+		t.Errorf("mensagem inesperada: %s", result.Message)
+	}
+	for name, status := range stepStatuses(result.Steps) {
+		if status != video_processing.StepStatusSkipped {
+			t.Errorf("etapa %s com status %s, esperado %s", name, status, video_processing.StepStatusSkipped)
+		}
+	}
+}
+
+func TestNewPipelineValidatesSteps(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []string
+		wantErr bool
+	}{
+		{"padrão", []string{video_processing.StepProbe, video_processing.StepExtractFrames, video_processing.StepZip, video_processing.StepThumbnail}, false},
+		{"somente probe", []string{video_processing.StepProbe}, false},
+		{"vazio", nil, true},
+		{"desconhecida", []string{video_processing.StepProbe, "upscale"}, true},
+		{"repetida", []string{video_processing.StepProbe, video_processing.StepProbe}, true},
+		{"fora de ordem", []string{video_processing.StepProbe, video_processing.StepZip, video_processing.StepExtractFrames}, true},
+		{"sem probe", []string{video_processing.StepExtractFrames}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := video_processing.NewPipeline(tt.steps)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPipeline(%v) erro = %v, esperado erro = %v", tt.steps, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type noopStep struct{ name string }
+
+func (s noopStep) Name() string                                  { return s.name }
+func (noopStep) Requires() []string                              { return []string{video_processing.StepProbe} }
+func (noopStep) Run(state *video_processing.PipelineState) error { return nil }
+
+func TestRegisterStepConcurrentWithNewPipeline(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("noop-%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			video_processing.RegisterStep(name, func() video_processing.Step { return noopStep{name: name} })
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := video_processing.NewPipeline([]string{video_processing.StepProbe, video_processing.StepThumbnail}); err != nil {
+				t.Errorf("erro inesperado: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := video_processing.NewPipeline([]string{video_processing.StepProbe, "noop-9"}); err != nil {
+		t.Errorf("etapa registrada não encontrada: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package video_processing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	progressInterval = 2 * time.Second
+	maxStderrTail    = 4096
+
+	// Tempo dado ao ffmpeg para encerrar após o SIGTERM quando o contexto é
+	// cancelado, antes de ser finalizado com SIGKILL.
+	ffmpegKillGrace = 10 * time.Second
+)
+
+type Progress struct {
+	Percent       int `json:"percent"`
+	Frame         int `json:"frame"`
+	EstimatedTime int `json:"estimated_time"`
+}
+
+type ProgressFunc func(Progress)
+
+// runFFmpeg executa o ffmpeg com -progress pipe:1 e converte os blocos
+// chave=valor do stdout em chamadas de onProgress. duration é a duração
+// esperada da saída em segundos; se for zero, apenas o frame é reportado.
+// O stderr completo é devolvido para quem precisar interpretar os logs.
+// O cancelamento de ctx interrompe o ffmpeg.
+func runFFmpeg(ctx context.Context, args []string, duration float64, onProgress ProgressFunc) (string, error) {
+	fullArgs := append([]string{"-hide_banner", "-nostats", "-progress", "pipe:1"}, args...)
+	cmd := exec.CommandContext(ctx, "ffmpeg", fullArgs...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = ffmpegKillGrace
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("erro ao abrir stdout do ffmpeg: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("erro ao iniciar ffmpeg: %w", err)
+	}
+
+	readProgress(stdout, duration, time.Now(), onProgress)
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return stderr.String(), fmt.Errorf("ffmpeg interrompido: %w", ctx.Err())
+		}
+		return stderr.String(), NewProcessingError(ffmpegFailureClass(err, stderr.String()), fmt.Errorf("%s\nOutput: %s", err.Error(), tail(stderr.String(), maxStderrTail)))
+	}
+
+	return stderr.String(), nil
+}
+
+// Mensagens do ffmpeg que indicam um arquivo que não pode ser decodificado,
+// em minúsculas.
+var ffmpegDecodeErrors = []string{
+	"invalid data found when processing input",
+	"moov atom not found",
+	"could not find codec parameters",
+	"error while decoding stream",
+	"invalid nal unit size",
+	"no decoder for",
+	"decoder not found",
+}
+
+// ffmpegFailureClass classifica uma saída com erro do ffmpeg. Só os erros de
+// decodificação conhecidos são permanentes; o processo morto por sinal (como
+// pelo OOM killer), o disco cheio e as falhas desconhecidas podem ter outro
+// resultado numa nova tentativa.
+func ffmpegFailureClass(err error, stderr string) ErrorClass {
+	if killedBySignal(err) {
+		return ErrorInternal
+	}
+
+	output := strings.ToLower(stderr)
+	if strings.Contains(output, "no space left on device") {
+		return ErrorInternal
+	}
+	for _, message := range ffmpegDecodeErrors {
+		if strings.Contains(output, message) {
+			return ErrorDecodeFailure
+		}
+	}
+	return ErrorInternal
+}
+
+// killedBySignal indica se o processo terminou por um sinal em vez de sair
+// com um código de erro.
+func killedBySignal(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled()
+}
+
+func readProgress(r io.Reader, duration float64, startedAt time.Time, onProgress ProgressFunc) {
+	scanner := bufio.NewScanner(r)
+
+	var current Progress
+	var lastReport time.Time
+	lastPercent := -1
+
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !found {
+			continue
+		}
+
+		switch key {
+		case "frame":
+			if frame, err := strconv.Atoi(value); err == nil {
+				current.Frame = frame
+			}
+		case "out_time_us", "out_time_ms":
+			// Apesar do nome, out_time_ms também é expresso em microssegundos.
+			outTime, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || duration <= 0 {
+				continue
+			}
+			current.Percent, current.EstimatedTime = estimateProgress(float64(outTime)/1e6, duration, time.Since(startedAt))
+		case "progress":
+			if value == "end" {
+				current.Percent = 100
+				current.EstimatedTime = 0
+			}
+
+			if onProgress == nil {
+				continue
+			}
+			if current.Percent == lastPercent && time.Since(lastReport) < progressInterval && value != "end" {
+				continue
+			}
+			onProgress(current)
+			lastPercent = current.Percent
+			lastReport = time.Now()
+		}
+	}
+}
+
+func estimateProgress(processed, duration float64, elapsed time.Duration) (int, int) {
+	if processed <= 0 {
+		return 0, 0
+	}
+
+	ratio := processed / duration
+	if ratio > 1 {
+		ratio = 1
+	}
+
+	percent := int(ratio * 100)
+	// 100% só é reportado quando o ffmpeg sinaliza progress=end.
+	if percent > 99 {
+		percent = 99
+	}
+
+	remaining := elapsed.Seconds() * (1 - ratio) / ratio
+	return percent, int(remaining + 0.5)
+}
+
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
@@ -0,0 +1,89 @@
+package video_processing
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// progressStream reproduz a saída de -progress pipe:1 de um vídeo de 10s.
+const progressStream = `frame=0
+fps=0.00
+out_time_us=0
+out_time_ms=0
+out_time=00:00:00.000000
+progress=continue
+frame=30
+fps=30.00
+out_time_us=1000000
+out_time_ms=1000000
+progress=continue
+frame=31
+out_time_us=1033333
+progress=continue
+frame=150
+out_time_ms=5000000
+progress=continue
+frame=300
+out_time_us=10000000
+progress=end
+`
+
+func TestReadProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration float64
+		want     []Progress
+	}{
+		{
+			name:     "com duração",
+			duration: 10,
+			// O bloco do frame 31 repete 10% dentro do intervalo e não é reportado.
+			want: []Progress{{Percent: 0, Frame: 0}, {Percent: 10, Frame: 30}, {Percent: 50, Frame: 150}, {Percent: 100, Frame: 300}},
+		},
+		{
+			name:     "sem duração",
+			duration: 0,
+			want:     []Progress{{Percent: 0, Frame: 0}, {Percent: 100, Frame: 300}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Progress
+			readProgress(strings.NewReader(progressStream), tt.duration, time.Now(), func(p Progress) {
+				p.EstimatedTime = 0
+				got = append(got, p)
+			})
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("progresso = %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateProgress(t *testing.T) {
+	tests := []struct {
+		processed   float64
+		duration    float64
+		elapsed     time.Duration
+		wantPercent int
+		wantETA     int
+	}{
+		{0, 10, time.Second, 0, 0},
+		{2.5, 10, 5 * time.Second, 25, 15},
+		{5, 10, 10 * time.Second, 50, 10},
+		{10, 10, 20 * time.Second, 99, 0},
+		{12, 10, 20 * time.Second, 99, 0},
+	}
+
+	for _, tt := range tests {
+		percent, eta := estimateProgress(tt.processed, tt.duration, tt.elapsed)
+		if percent != tt.wantPercent || eta != tt.wantETA {
+			t.Errorf("estimateProgress(%.1f, %.1f, %s) = %d%%, %ds, esperado %d%%, %ds",
+				tt.processed, tt.duration, tt.elapsed, percent, eta, tt.wantPercent, tt.wantETA)
+		}
+	}
+}
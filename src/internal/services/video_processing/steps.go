@@ -0,0 +1,312 @@
+package video_processing
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"src/internal/models"
+)
+
+type probeStep struct{}
+
+func (probeStep) Name() string       { return StepProbe }
+func (probeStep) Requires() []string { return nil }
+
+func (probeStep) Run(state *PipelineState) error {
+	info, err := state.Extractor.Probe(state.Ctx, state.VideoPath)
+	if err != nil {
+		log.Printf("❌ Vídeo rejeitado: VideoID=%d: %v", state.Job.VideoID, err)
+		return classify(ErrorInvalidInput, fmt.Errorf("vídeo inválido: %w", err))
+	}
+
+	log.Printf("🔍 Vídeo analisado: VideoID=%d, Container=%s, Codec=%s, %dx%d, %.2fs",
+		state.Job.VideoID, info.Container, info.VideoCodec, info.Width, info.Height, info.Duration)
+
+	state.Info = info
+	state.Result.VideoInfo = info
+
+	timeout := processingTimeout(info.Duration)
+	log.Printf("⏳ Tempo limite do job: VideoID=%d, %s", state.Job.VideoID, timeout)
+	state.setTimeout(timeout)
+	return nil
+}
+
+type extractFramesStep struct{}
+
+func (extractFramesStep) Name() string       { return StepExtractFrames }
+func (extractFramesStep) Requires() []string { return []string{StepProbe} }
+
+func (extractFramesStep) Run(state *PipelineState) error {
+	opts := state.Options
+
+	frames, timestamps, err := state.Extractor.ExtractFrames(state.Ctx, state.VideoPath, state.WorkDir, opts, extractionDuration(opts, state.Info.Duration), state.OnProgress)
+	if err != nil {
+		return err
+	}
+	if len(frames) == 0 {
+		return NewProcessingError(ErrorDecodeFailure, fmt.Errorf("nenhum frame foi extraído do vídeo"))
+	}
+
+	fmt.Printf("📸 Extraídos %d frames\n", len(frames))
+
+	frameInfos, err := buildFrameInfos(frames, timestamps)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar manifest dos frames: %w", err)
+	}
+
+	imageNames := make([]string, len(frames))
+	for i, frame := range frames {
+		imageNames[i] = filepath.Base(frame)
+	}
+
+	state.FramePaths = frames
+	state.Frames = frameInfos
+	state.Result.FrameCount = len(frames)
+	state.Result.Images = imageNames
+	state.Result.Frames = frameInfos
+	return nil
+}
+
+type zipStep struct{}
+
+func (zipStep) Name() string       { return StepZip }
+func (zipStep) Requires() []string { return []string{StepExtractFrames} }
+
+func (zipStep) Run(state *PipelineState) error {
+	manifest := buildManifest(state.Job, state.Options, state.Info, state.Frames)
+
+	zipFilename := fmt.Sprintf("%s.zip", state.BaseName)
+	zipPath := filepath.Join(state.OutputDir, zipFilename)
+
+	if err := createZipFile(state.FramePaths, zipPath, manifest); err != nil {
+		return fmt.Errorf("erro ao criar arquivo ZIP: %w", err)
+	}
+
+	fmt.Printf("✅ ZIP criado: %s\n", zipPath)
+
+	state.Result.ZipPath = zipFilename
+	state.Result.Manifest = manifest
+	state.addOutput(OutputFile{
+		Kind:        OutputZip,
+		Path:        zipPath,
+		ObjectName:  outputObjectName(state.Job, zipFilename),
+		ContentType: "application/zip",
+	})
+	return nil
+}
+
+type thumbnailStep struct{}
+
+func (thumbnailStep) Name() string       { return StepThumbnail }
+func (thumbnailStep) Requires() []string { return []string{StepProbe} }
+func (thumbnailStep) Optional() bool     { return true }
+
+func (thumbnailStep) Run(state *PipelineState) error {
+	posterPath, err := generatePoster(state.Ctx, state.Extractor, state.VideoPath, state.OutputDir, state.BaseName, state.Info)
+	if err != nil {
+		return fmt.Errorf("não foi possível gerar o poster do vídeo: %w", err)
+	}
+
+	state.addOutput(OutputFile{
+		Kind:        OutputPoster,
+		Path:        posterPath,
+		ObjectName:  thumbnailObjectName(state.Job, filepath.Base(posterPath)),
+		ContentType: "image/jpeg",
+	})
+	return nil
+}
+
+type previewStep struct{}
+
+func (previewStep) Name() string       { return StepPreview }
+func (previewStep) Requires() []string { return []string{StepProbe} }
+
+func (previewStep) Run(state *PipelineState) error {
+	opts := state.Options.Preview
+	if opts == nil {
+		opts, _ = normalizePreviewOptions(&models.PreviewOptions{})
+	}
+
+	previewPath, err := generatePreview(state.Ctx, state.Extractor, state.VideoPath, state.OutputDir, state.BaseName, opts, state.Info)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🎞️ Preview gerado: %s\n", previewPath)
+
+	state.addOutput(OutputFile{
+		Kind:        OutputPreview,
+		Path:        previewPath,
+		ObjectName:  outputObjectName(state.Job, filepath.Base(previewPath)),
+		ContentType: previewContentType(opts.Format),
+	})
+	return nil
+}
+
+type contactSheetStep struct{}
+
+func (contactSheetStep) Name() string       { return StepContactSheet }
+func (contactSheetStep) Requires() []string { return []string{StepExtractFrames} }
+
+func (contactSheetStep) Run(state *PipelineState) error {
+	opts := state.Options
+	if opts.ContactSheet == nil {
+		withSheet := *opts
+		withSheet.ContactSheet, _ = normalizeContactSheetOptions(&models.ContactSheetOptions{})
+		opts = &withSheet
+	}
+
+	sheets, track, err := generateContactSheets(state.Ctx, state.Extractor, state.FramePaths, state.Frames, opts, state.Info, state.WorkDir, state.OutputDir, state.BaseName)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar contact sheet: %w", err)
+	}
+
+	for _, sheet := range sheets {
+		state.addOutput(OutputFile{
+			Kind:        OutputContactSheet,
+			Path:        sheet,
+			ObjectName:  outputObjectName(state.Job, filepath.Base(sheet)),
+			ContentType: "image/jpeg",
+		})
+	}
+	state.addOutput(OutputFile{
+		Kind:        OutputThumbnailTrack,
+		Path:        track,
+		ObjectName:  outputObjectName(state.Job, filepath.Base(track)),
+		ContentType: "text/vtt",
+	})
+
+	fmt.Printf("🖼️ %d contact sheets gerados\n", len(sheets))
+	return nil
+}
+
+type transcodeStep struct{}
+
+func (transcodeStep) Name() string       { return StepTranscode }
+func (transcodeStep) Requires() []string { return []string{StepProbe} }
+
+func (transcodeStep) Run(state *PipelineState) error {
+	opts := state.Options.Transcode
+	if opts == nil {
+		opts, _ = normalizeTranscodeOptions(&models.TranscodeOptions{HLS: true})
+	}
+
+	renditions := selectRenditions(opts, state.Info)
+
+	formats := 0
+	if opts.HLS {
+		formats++
+	}
+	if opts.DASH {
+		formats++
+	}
+	extra := transcodeTimeout(state.Info.Duration, len(renditions), formats)
+	log.Printf("⏳ Tempo limite do job estendido em %s para o transcode: VideoID=%d", extra, state.Job.VideoID)
+	state.extendTimeout(extra)
+
+	if opts.HLS {
+		hlsDir := filepath.Join(state.OutputDir, state.BaseName+"_hls")
+		if _, err := transcodeHLS(state.Ctx, state.Extractor, state.VideoPath, hlsDir, renditions, state.Info, opts.SegmentDuration); err != nil {
+			return err
+		}
+
+		hlsOutputs, err := collectStreamingOutputs(state.Job, OutputHLS, hlsDir, filepath.Base(hlsDir))
+		if err != nil {
+			return err
+		}
+		state.Result.Outputs = append(state.Result.Outputs, hlsOutputs...)
+		state.Result.HLSPlaylist = outputObjectName(state.Job, filepath.Base(hlsDir)+"/"+hlsMasterPlaylist)
+
+		fmt.Printf("📺 HLS gerado com %d renditions\n", len(renditions))
+	}
+
+	if opts.DASH {
+		dashDir := filepath.Join(state.OutputDir, state.BaseName+"_dash")
+		if _, err := transcodeDASH(state.Ctx, state.Extractor, state.VideoPath, dashDir, renditions, state.Info, opts.SegmentDuration); err != nil {
+			return err
+		}
+
+		dashOutputs, err := collectStreamingOutputs(state.Job, OutputDASH, dashDir, filepath.Base(dashDir))
+		if err != nil {
+			return err
+		}
+		state.Result.Outputs = append(state.Result.Outputs, dashOutputs...)
+		state.Result.DASHManifest = outputObjectName(state.Job, filepath.Base(dashDir)+"/"+dashManifest)
+
+		fmt.Printf("📺 DASH gerado com %d renditions\n", len(renditions))
+	}
+
+	return nil
+}
+
+type audioStep struct{}
+
+func (audioStep) Name() string       { return StepAudio }
+func (audioStep) Requires() []string { return []string{StepProbe} }
+
+func (audioStep) Run(state *PipelineState) error {
+	if len(state.Info.AudioTracks) == 0 {
+		return NewProcessingError(ErrorInvalidInput, fmt.Errorf("o vídeo não possui trilha de áudio"))
+	}
+
+	opts := state.Options
+	if opts.Audio == nil {
+		withAudio := *opts
+		withAudio.Audio, _ = normalizeAudioOptions(&models.AudioOptions{})
+		opts = &withAudio
+	}
+
+	duration := extractionDuration(opts, state.Info.Duration)
+	audioPath, err := extractAudio(state.Ctx, state.Extractor, state.VideoPath, state.OutputDir, state.BaseName, opts, duration, state.OnProgress)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🎧 Áudio extraído: %s\n", audioPath)
+
+	audioResult := &AudioResult{
+		ObjectName: outputObjectName(state.Job, filepath.Base(audioPath)),
+		Format:     opts.Audio.Format,
+		Bitrate:    opts.Audio.Bitrate,
+		Duration:   duration,
+	}
+	state.addOutput(OutputFile{
+		Kind:        OutputAudio,
+		Path:        audioPath,
+		ObjectName:  audioResult.ObjectName,
+		ContentType: audioContentType(opts.Audio.Format),
+	})
+
+	if opts.Audio.Waveform {
+		waveformPath, err := generateWaveform(state.Ctx, state.Extractor, state.VideoPath, state.OutputDir, state.BaseName, opts)
+		if err != nil {
+			return err
+		}
+
+		audioResult.Waveform = outputObjectName(state.Job, filepath.Base(waveformPath))
+		state.addOutput(OutputFile{
+			Kind:        OutputWaveform,
+			Path:        waveformPath,
+			ObjectName:  audioResult.Waveform,
+			ContentType: "image/png",
+		})
+	}
+
+	if opts.Audio.Peaks {
+		peaksPath, err := generatePeaks(state.Ctx, state.Extractor, state.VideoPath, state.WorkDir, state.OutputDir, state.BaseName, opts)
+		if err != nil {
+			return err
+		}
+
+		audioResult.Peaks = outputObjectName(state.Job, filepath.Base(peaksPath))
+		state.addOutput(OutputFile{
+			Kind:        OutputPeaks,
+			Path:        peaksPath,
+			ObjectName:  audioResult.Peaks,
+			ContentType: "application/json",
+		})
+	}
+
+	state.Result.Audio = audioResult
+	return nil
+}
@@ -0,0 +1,119 @@
+package video_processing
+
+import (
+	"context"
+	"os"
+	"src/internal/models"
+	"src/internal/storage/storagetest"
+	"testing"
+	"time"
+)
+
+// stallingExtractor espera delay em cada chamada ao ffprobe e ao ffmpeg, ou
+// até o contexto ser cancelado, simulando um processo travado.
+type stallingExtractor struct {
+	probeDelay  time.Duration
+	ffmpegDelay time.Duration
+}
+
+func (s *stallingExtractor) RunFFmpeg(ctx context.Context, args []string, duration float64, onProgress ProgressFunc) (string, error) {
+	select {
+	case <-time.After(s.ffmpegDelay):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	return "", os.WriteFile(args[len(args)-1], nil, 0644)
+}
+
+func (s *stallingExtractor) Probe(ctx context.Context, videoPath string) (*models.VideoMetadata, error) {
+	select {
+	case <-time.After(s.probeDelay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &models.VideoMetadata{Duration: 10, VideoCodec: "h264", Width: 640, Height: 360, FrameRate: 25}, nil
+}
+
+func (s *stallingExtractor) ExtractFrames(ctx context.Context, videoPath, outputDir string, opts *models.ExtractionOptions, duration float64, onProgress ProgressFunc) ([]string, []float64, error) {
+	return nil, nil, nil
+}
+
+func newTimeoutTestProcessor(t *testing.T, extractor FrameExtractor, startTimeout time.Duration) *Processor {
+	t.Helper()
+
+	store := storagetest.NewMemoryStore()
+	store.Put("1/input/video.mp4", []byte("fake video"), "video/mp4")
+
+	processor := NewProcessorWithDependencies(store, extractor, t.TempDir())
+	processor.startTimeout = startTimeout
+	return processor
+}
+
+func timeoutTestJob() *models.VideoProcessingJob {
+	return &models.VideoProcessingJob{
+		ID:       "job_test",
+		VideoID:  42,
+		UserID:   1,
+		VideoURL: "http://minio:9000/videos/1/input/video.mp4",
+		Options:  &models.ExtractionOptions{Steps: []string{StepProbe, StepPreview}},
+	}
+}
+
+func TestProcessingTimeout(t *testing.T) {
+	if got := processingTimeout(6); got != minJobTimeout+time.Minute {
+		t.Errorf("processingTimeout(6) = %s, esperado %s", got, minJobTimeout+time.Minute)
+	}
+	if got := processingTimeout(24 * 3600); got != maxJobTimeout {
+		t.Errorf("processingTimeout(24h) = %s, esperado %s", got, maxJobTimeout)
+	}
+}
+
+func TestTranscodeTimeoutScalesWithRenditionsAndFormats(t *testing.T) {
+	const duration = 3 * 3600
+
+	if got := transcodeTimeout(duration, 1, 1); got != maxJobTimeout {
+		t.Errorf("transcodeTimeout(3h, 1 rendition, 1 formato) = %s, esperado %s", got, maxJobTimeout)
+	}
+	if got := transcodeTimeout(duration, 4, 2); got != 8*maxJobTimeout {
+		t.Errorf("transcodeTimeout(3h, 4 renditions, 2 formatos) = %s, esperado %s", got, 8*maxJobTimeout)
+	}
+}
+
+func TestExtendTimeoutMovesJobDeadline(t *testing.T) {
+	started := time.Now()
+	state := &PipelineState{Ctx: context.Background(), parentCtx: context.Background(), started: started}
+	defer state.close()
+
+	state.setTimeout(maxJobTimeout)
+	state.extendTimeout(3 * maxJobTimeout)
+
+	deadline, ok := state.Ctx.Deadline()
+	if !ok || !deadline.Equal(started.Add(4*maxJobTimeout)) {
+		t.Errorf("prazo = %s, esperado %s", deadline, started.Add(4*maxJobTimeout))
+	}
+}
+
+func TestProcessVideoAbortsStalledProbe(t *testing.T) {
+	processor := newTimeoutTestProcessor(t, &stallingExtractor{probeDelay: time.Minute}, 50*time.Millisecond)
+
+	started := time.Now()
+	result := processor.ProcessVideo(context.Background(), timeoutTestJob(), nil)
+
+	if result.Status != models.StatusFailed || result.ErrorClass != ErrorTimeout {
+		t.Fatalf("status = %s, classe = %s, esperado falha %s", result.Status, result.ErrorClass, ErrorTimeout)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("probe travado abortado após %s, esperado o prazo inicial do job", elapsed)
+	}
+}
+
+func TestProcessVideoExtendsDeadlineAfterProbe(t *testing.T) {
+	processor := newTimeoutTestProcessor(t, &stallingExtractor{ffmpegDelay: 200 * time.Millisecond}, 50*time.Millisecond)
+
+	result := processor.ProcessVideo(context.Background(), timeoutTestJob(), nil)
+	defer processor.ReleaseWorkDir(result)
+
+	if result.Status != models.StatusCompleted {
+		t.Fatalf("status = %s, esperado %s: %s", result.Status, models.StatusCompleted, result.Message)
+	}
+}
@@ -0,0 +1,264 @@
+package video_processing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"src/internal/models"
+	"strings"
+)
+
+const (
+	DefaultSegmentDuration = 6
+	MinSegmentDuration     = 2
+	MaxSegmentDuration     = 20
+
+	hlsMasterPlaylist = "master.m3u8"
+	dashManifest      = "manifest.mpd"
+)
+
+type Rendition struct {
+	Name         string
+	Height       int
+	VideoBitrate int
+	AudioBitrate int
+}
+
+var renditionLadder = []Rendition{
+	{Name: "1080p", Height: 1080, VideoBitrate: 5000, AudioBitrate: 192},
+	{Name: "720p", Height: 720, VideoBitrate: 2800, AudioBitrate: 128},
+	{Name: "480p", Height: 480, VideoBitrate: 1400, AudioBitrate: 128},
+	{Name: "360p", Height: 360, VideoBitrate: 800, AudioBitrate: 96},
+}
+
+func normalizeTranscodeOptions(opts *models.TranscodeOptions) (*models.TranscodeOptions, error) {
+	normalized := *opts
+
+	if !normalized.HLS && !normalized.DASH {
+		return nil, fmt.Errorf("transcode exige ao menos um formato de saída (hls ou dash)")
+	}
+
+	if normalized.SegmentDuration == 0 {
+		normalized.SegmentDuration = DefaultSegmentDuration
+	}
+	if normalized.SegmentDuration < MinSegmentDuration || normalized.SegmentDuration > MaxSegmentDuration {
+		return nil, fmt.Errorf("segment_duration deve estar entre %d e %d segundos", MinSegmentDuration, MaxSegmentDuration)
+	}
+
+	renditions := make([]string, 0, len(normalized.Renditions))
+	for _, name := range normalized.Renditions {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := findRendition(name); !ok {
+			return nil, fmt.Errorf("rendition não suportada: %s (use 1080p, 720p, 480p ou 360p)", name)
+		}
+		renditions = append(renditions, name)
+	}
+	normalized.Renditions = renditions
+
+	return &normalized, nil
+}
+
+func findRendition(name string) (Rendition, bool) {
+	for _, rendition := range renditionLadder {
+		if rendition.Name == name {
+			return rendition, true
+		}
+	}
+	return Rendition{}, false
+}
+
+// selectRenditions devolve as renditions pedidas ou, se nenhuma foi informada,
+// todas as do ladder que não ultrapassam a altura do vídeo original.
+func selectRenditions(opts *models.TranscodeOptions, info *models.VideoMetadata) []Rendition {
+	if len(opts.Renditions) > 0 {
+		var selected []Rendition
+		for _, rendition := range renditionLadder {
+			for _, name := range opts.Renditions {
+				if rendition.Name == name {
+					selected = append(selected, rendition)
+					break
+				}
+			}
+		}
+		return selected
+	}
+
+	sourceHeight := info.Height
+	if info.Rotation%180 != 0 {
+		sourceHeight = info.Width
+	}
+
+	var selected []Rendition
+	for _, rendition := range renditionLadder {
+		if rendition.Height <= sourceHeight {
+			selected = append(selected, rendition)
+		}
+	}
+	if len(selected) == 0 {
+		selected = append(selected, renditionLadder[len(renditionLadder)-1])
+	}
+	return selected
+}
+
+func transcodeHLS(ctx context.Context, ffmpeg FFmpegRunner, videoPath, outputDir string, renditions []Rendition, info *models.VideoMetadata, segmentDuration int) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório HLS: %w", err)
+	}
+
+	hasAudio := len(info.AudioTracks) > 0
+
+	args := []string{"-i", videoPath, "-filter_complex", scaleFilterGraph(renditions)}
+	var streamMap []string
+
+	for i, rendition := range renditions {
+		args = append(args, videoEncodingArgs(i, rendition, segmentDuration, info)...)
+		entry := fmt.Sprintf("v:%d", i)
+		if hasAudio {
+			args = append(args,
+				"-map", "0:a:0",
+				fmt.Sprintf("-c:a:%d", i), "aac",
+				fmt.Sprintf("-b:a:%d", i), fmt.Sprintf("%dk", rendition.AudioBitrate),
+				"-ac", "2",
+			)
+			entry += fmt.Sprintf(",a:%d", i)
+		}
+		streamMap = append(streamMap, entry+",name:"+rendition.Name)
+	}
+
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", fmt.Sprintf("%d", segmentDuration),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(outputDir, "%v_%03d.ts"),
+		"-master_pl_name", hlsMasterPlaylist,
+		"-var_stream_map", strings.Join(streamMap, " "),
+		"-y", filepath.Join(outputDir, "%v.m3u8"),
+	)
+
+	if _, err := ffmpeg.RunFFmpeg(ctx, args, 0, nil); err != nil {
+		return "", fmt.Errorf("erro ao gerar HLS: %w", err)
+	}
+
+	return filepath.Join(outputDir, hlsMasterPlaylist), nil
+}
+
+func transcodeDASH(ctx context.Context, ffmpeg FFmpegRunner, videoPath, outputDir string, renditions []Rendition, info *models.VideoMetadata, segmentDuration int) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório DASH: %w", err)
+	}
+
+	args := []string{"-i", videoPath, "-filter_complex", scaleFilterGraph(renditions)}
+	for i, rendition := range renditions {
+		args = append(args, videoEncodingArgs(i, rendition, segmentDuration, info)...)
+	}
+
+	adaptationSets := "id=0,streams=v"
+	if len(info.AudioTracks) > 0 {
+		// Uma única trilha de áudio é compartilhada por todas as renditions.
+		args = append(args,
+			"-map", "0:a:0",
+			"-c:a", "aac",
+			"-b:a", fmt.Sprintf("%dk", renditions[0].AudioBitrate),
+			"-ac", "2",
+		)
+		adaptationSets += " id=1,streams=a"
+	}
+
+	manifestPath := filepath.Join(outputDir, dashManifest)
+	args = append(args,
+		"-f", "dash",
+		"-seg_duration", fmt.Sprintf("%d", segmentDuration),
+		"-use_template", "1",
+		"-use_timeline", "1",
+		"-adaptation_sets", adaptationSets,
+		"-y", manifestPath,
+	)
+
+	if _, err := ffmpeg.RunFFmpeg(ctx, args, 0, nil); err != nil {
+		return "", fmt.Errorf("erro ao gerar DASH: %w", err)
+	}
+
+	return manifestPath, nil
+}
+
+func scaleFilterGraph(renditions []Rendition) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[0:v]split=%d", len(renditions))
+	for i := range renditions {
+		fmt.Fprintf(&b, "[s%d]", i)
+	}
+	for i, rendition := range renditions {
+		fmt.Fprintf(&b, ";[s%d]scale=-2:%d[v%d]", i, rendition.Height, i)
+	}
+	return b.String()
+}
+
+func videoEncodingArgs(index int, rendition Rendition, segmentDuration int, info *models.VideoMetadata) []string {
+	frameRate := info.FrameRate
+	if frameRate <= 0 {
+		frameRate = 30
+	}
+	// GOP alinhado à duração do segmento para que todos os segmentos comecem em keyframe.
+	gop := int(frameRate * float64(segmentDuration))
+
+	return []string{
+		"-map", fmt.Sprintf("[v%d]", index),
+		fmt.Sprintf("-c:v:%d", index), "libx264",
+		fmt.Sprintf("-b:v:%d", index), fmt.Sprintf("%dk", rendition.VideoBitrate),
+		fmt.Sprintf("-maxrate:v:%d", index), fmt.Sprintf("%dk", rendition.VideoBitrate*107/100),
+		fmt.Sprintf("-bufsize:v:%d", index), fmt.Sprintf("%dk", rendition.VideoBitrate*3/2),
+		fmt.Sprintf("-g:v:%d", index), fmt.Sprintf("%d", gop),
+		fmt.Sprintf("-keyint_min:v:%d", index), fmt.Sprintf("%d", gop),
+		fmt.Sprintf("-sc_threshold:v:%d", index), "0",
+		fmt.Sprintf("-preset:v:%d", index), "veryfast",
+		fmt.Sprintf("-pix_fmt:v:%d", index), "yuv420p",
+	}
+}
+
+// collectStreamingOutputs lista todos os arquivos gerados em dir (playlists,
+// manifests e segmentos) como saídas a serem enviadas ao MinIO sob prefix.
+func collectStreamingOutputs(job *models.VideoProcessingJob, kind, dir, prefix string) ([]OutputFile, error) {
+	var outputs []OutputFile
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		outputs = append(outputs, OutputFile{
+			Kind:        kind,
+			Path:        path,
+			ObjectName:  outputObjectName(job, prefix+"/"+filepath.ToSlash(rel)),
+			ContentType: streamingContentType(path),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar arquivos de %s: %w", kind, err)
+	}
+
+	return outputs, nil
+}
+
+func streamingContentType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".m3u8":
+		return "application/vnd.apple.mpegurl"
+	case ".ts":
+		return "video/mp2t"
+	case ".mpd":
+		return "application/dash+xml"
+	case ".m4s":
+		return "video/iso.segment"
+	case ".mp4":
+		return "video/mp4"
+	default:
+		return "application/octet-stream"
+	}
+}
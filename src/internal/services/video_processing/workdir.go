@@ -0,0 +1,98 @@
+package video_processing
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"src/internal/models"
+	"strings"
+	"sync"
+)
+
+const jobDirPrefix = "job_"
+
+func DefaultWorkRoot() string {
+	return filepath.Join(os.TempDir(), "video-processing")
+}
+
+// workDirs cria um diretório exclusivo por job sob root e mantém o registro
+// dos que estão em uso, para que a limpeza de órfãos nunca remova o
+// diretório de um job em andamento.
+type workDirs struct {
+	root   string
+	mu     sync.Mutex
+	active map[string]struct{}
+}
+
+func newWorkDirs(root string) *workDirs {
+	if root == "" {
+		root = DefaultWorkRoot()
+	}
+	return &workDirs{
+		root:   root,
+		active: make(map[string]struct{}),
+	}
+}
+
+func (w *workDirs) create(job *models.VideoProcessingJob) (string, error) {
+	if err := os.MkdirAll(w.root, 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório raiz de trabalho: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(w.root, fmt.Sprintf("%s%d_%d_", jobDirPrefix, job.UserID, job.VideoID))
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar diretório do job: %w", err)
+	}
+
+	w.mu.Lock()
+	w.active[dir] = struct{}{}
+	w.mu.Unlock()
+
+	return dir, nil
+}
+
+func (w *workDirs) release(dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf("⚠️ Erro ao remover diretório de trabalho %s: %v", dir, err)
+	}
+
+	w.mu.Lock()
+	delete(w.active, dir)
+	w.mu.Unlock()
+}
+
+// cleanupOrphans remove os diretórios de job que não estão em uso, deixados
+// para trás quando o processo é encerrado no meio de um processamento.
+func (w *workDirs) cleanupOrphans() (int, error) {
+	entries, err := os.ReadDir(w.root)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar diretório de trabalho: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), jobDirPrefix) {
+			continue
+		}
+
+		dir := filepath.Join(w.root, entry.Name())
+		if _, inUse := w.active[dir]; inUse {
+			continue
+		}
+
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("⚠️ Erro ao remover diretório órfão %s: %v", dir, err)
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
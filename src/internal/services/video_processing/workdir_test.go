@@ -0,0 +1,69 @@
+package video_processing
+
+import (
+	"os"
+	"path/filepath"
+	"src/internal/models"
+	"testing"
+)
+
+func TestWorkDirsAreUniquePerJob(t *testing.T) {
+	dirs := newWorkDirs(t.TempDir())
+	job := &models.VideoProcessingJob{UserID: 1, VideoID: 7}
+
+	first, err := dirs.create(job)
+	if err != nil {
+		t.Fatalf("erro ao criar diretório: %v", err)
+	}
+	second, err := dirs.create(job)
+	if err != nil {
+		t.Fatalf("erro ao criar diretório: %v", err)
+	}
+
+	if first == second {
+		t.Fatalf("jobs do mesmo usuário receberam o mesmo diretório: %s", first)
+	}
+
+	dirs.release(first)
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("diretório %s deveria ter sido removido", first)
+	}
+	if _, err := os.Stat(second); err != nil {
+		t.Errorf("diretório %s não deveria ser afetado: %v", second, err)
+	}
+}
+
+func TestCleanupOrphansKeepsActiveDirs(t *testing.T) {
+	root := t.TempDir()
+	dirs := newWorkDirs(root)
+
+	active, err := dirs.create(&models.VideoProcessingJob{UserID: 1, VideoID: 1})
+	if err != nil {
+		t.Fatalf("erro ao criar diretório: %v", err)
+	}
+
+	orphan := filepath.Join(root, jobDirPrefix+"1_2_123")
+	unrelated := filepath.Join(root, "cache")
+	for _, dir := range []string{orphan, unrelated} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("erro ao criar %s: %v", dir, err)
+		}
+	}
+
+	removed, err := dirs.cleanupOrphans()
+	if err != nil {
+		t.Fatalf("erro na limpeza: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removidos = %d, esperado 1", removed)
+	}
+
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("diretório órfão %s deveria ter sido removido", orphan)
+	}
+	for _, dir := range []string{active, unrelated} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("diretório %s não deveria ser removido: %v", dir, err)
+		}
+	}
+}
@@ -14,10 +14,25 @@ import (
 
 type MinioClient struct {
 	client     *minio.Client
+	core       *minio.Core
 	bucketName string
 	endpoint   string
 }
 
+type UploadedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// MultipartUpload é um upload multipart ainda não concluído no bucket.
+// LastActivity é o envio da parte mais recente, ou o início do upload se
+// nenhuma parte foi enviada.
+type MultipartUpload struct {
+	ObjectName   string
+	UploadID     string
+	LastActivity time.Time
+}
+
 func NewMinioClient(endpoint, accessKey, secretKey, bucketName string) (*MinioClient, error) {
 	client, err := minio.New(endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
@@ -34,6 +49,7 @@ func NewMinioClient(endpoint, accessKey, secretKey, bucketName string) (*MinioCl
 
 	return &MinioClient{
 		client:     client,
+		core:       &minio.Core{Client: client},
 		bucketName: bucketName,
 		endpoint:   endpoint,
 	}, nil
@@ -55,8 +71,12 @@ func prepareBucket(client *minio.Client, bucketName string) (*MinioClient, error
 }
 
 func (m *MinioClient) UploadFile(ctx context.Context, objectName string, file io.Reader, size int64) (string, error) {
+	return m.UploadFileWithContentType(ctx, objectName, file, size, "video/mp4")
+}
+
+func (m *MinioClient) UploadFileWithContentType(ctx context.Context, objectName string, file io.Reader, size int64, contentType string) (string, error) {
 	_, err := m.client.PutObject(ctx, m.bucketName, objectName, file, size, minio.PutObjectOptions{
-		ContentType: "video/mp4",
+		ContentType: contentType,
 	})
 	if err != nil {
 		return "", fmt.Errorf("erro ao fazer upload: %w", err)
@@ -66,6 +86,99 @@ func (m *MinioClient) UploadFile(ctx context.Context, objectName string, file io
 	return url, nil
 }
 
+func (m *MinioClient) NewMultipartUpload(ctx context.Context, objectName string) (string, error) {
+	uploadID, err := m.core.NewMultipartUpload(ctx, m.bucketName, objectName, minio.PutObjectOptions{
+		ContentType: "video/mp4",
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao iniciar upload multipart: %w", err)
+	}
+	return uploadID, nil
+}
+
+func (m *MinioClient) UploadPart(ctx context.Context, objectName, uploadID string, partNumber int, data io.Reader, size int64) (*UploadedPart, error) {
+	part, err := m.core.PutObjectPart(ctx, m.bucketName, objectName, uploadID, partNumber, data, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao enviar parte %d: %w", partNumber, err)
+	}
+	return &UploadedPart{
+		PartNumber: part.PartNumber,
+		ETag:       part.ETag,
+	}, nil
+}
+
+func (m *MinioClient) CompleteMultipartUpload(ctx context.Context, objectName, uploadID string, parts []UploadedPart) (string, error) {
+	completeParts := make([]minio.CompletePart, len(parts))
+	for i, part := range parts {
+		completeParts[i] = minio.CompletePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		}
+	}
+
+	_, err := m.core.CompleteMultipartUpload(ctx, m.bucketName, objectName, uploadID, completeParts, minio.PutObjectOptions{
+		ContentType: "video/mp4",
+	})
+	if err != nil {
+		return "", fmt.Errorf("erro ao concluir upload multipart: %w", err)
+	}
+
+	url := fmt.Sprintf("http://%s/%s/%s", m.endpoint, m.bucketName, objectName)
+	return url, nil
+}
+
+func (m *MinioClient) AbortMultipartUpload(ctx context.Context, objectName, uploadID string) error {
+	if err := m.core.AbortMultipartUpload(ctx, m.bucketName, objectName, uploadID); err != nil {
+		return fmt.Errorf("erro ao abortar upload multipart: %w", err)
+	}
+	return nil
+}
+
+// ListMultipartUploads lista os uploads multipart não concluídos do bucket.
+func (m *MinioClient) ListMultipartUploads(ctx context.Context) ([]MultipartUpload, error) {
+	var uploads []MultipartUpload
+	for info := range m.client.ListIncompleteUploads(ctx, m.bucketName, "", true) {
+		if info.Err != nil {
+			return nil, fmt.Errorf("erro ao listar uploads multipart: %w", info.Err)
+		}
+
+		lastActivity, err := m.lastPartUpload(ctx, info.Key, info.UploadID)
+		if err != nil {
+			return nil, err
+		}
+		if lastActivity.Before(info.Initiated) {
+			lastActivity = info.Initiated
+		}
+
+		uploads = append(uploads, MultipartUpload{
+			ObjectName:   info.Key,
+			UploadID:     info.UploadID,
+			LastActivity: lastActivity,
+		})
+	}
+	return uploads, nil
+}
+
+func (m *MinioClient) lastPartUpload(ctx context.Context, objectName, uploadID string) (time.Time, error) {
+	var last time.Time
+	marker := 0
+	for {
+		result, err := m.core.ListObjectParts(ctx, m.bucketName, objectName, uploadID, marker, 1000)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("erro ao listar partes do upload multipart: %w", err)
+		}
+		for _, part := range result.ObjectParts {
+			if part.LastModified.After(last) {
+				last = part.LastModified
+			}
+		}
+		if !result.IsTruncated {
+			return last, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
 func (m *MinioClient) UploadString(ctx context.Context, objectName string, content string) error {
 	reader := strings.NewReader(content)
 
@@ -83,8 +196,8 @@ func (m *MinioClient) DeleteFile(ctx context.Context, objectName string) error {
 	return m.client.RemoveObject(ctx, m.bucketName, objectName, minio.RemoveObjectOptions{})
 }
 
-func (m *MinioClient) GetFileURL(objectName string, expires time.Duration) (string, error) {
-	url, err := m.client.PresignedGetObject(context.Background(), m.bucketName, objectName, expires, nil)
+func (m *MinioClient) GetFileURL(ctx context.Context, objectName string, expires time.Duration) (string, error) {
+	url, err := m.client.PresignedGetObject(ctx, m.bucketName, objectName, expires, nil)
 	if err != nil {
 		return "", fmt.Errorf("erro ao gerar URL: %w", err)
 	}
@@ -98,6 +211,13 @@ func (m *MinioClient) DownloadFile(ctx context.Context, objectName, localPath st
 	}
 	defer obj.Close()
 
+	if _, err := obj.Stat(); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return fmt.Errorf("%w: %s", ErrNotFound, objectName)
+		}
+		return fmt.Errorf("erro ao obter objeto do MinIO: %w", err)
+	}
+
 	localFile, err := os.Create(localPath)
 	if err != nil {
 		return fmt.Errorf("erro ao criar arquivo local: %w", err)
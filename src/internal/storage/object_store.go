@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound indica que o objeto solicitado não existe no bucket.
+var ErrNotFound = errors.New("objeto não encontrado")
+
+// ObjectStore é o subconjunto do armazenamento de objetos usado pelo
+// processamento de vídeos, implementado pelo MinioClient e, nos testes, pelo storagetest.MemoryStore.
+type ObjectStore interface {
+	UploadFileWithContentType(ctx context.Context, objectName string, file io.Reader, size int64, contentType string) (string, error)
+	UploadString(ctx context.Context, objectName string, content string) error
+	DownloadFile(ctx context.Context, objectName, localPath string) error
+	DeleteFile(ctx context.Context, objectName string) error
+	GetFileURL(ctx context.Context, objectName string, expires time.Duration) (string, error)
+}
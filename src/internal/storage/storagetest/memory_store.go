@@ -0,0 +1,105 @@
+// Package storagetest oferece um storage.ObjectStore em memória para os
+// testes.
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"src/internal/storage"
+	"sync"
+	"time"
+)
+
+type MemoryObject struct {
+	Data        []byte
+	ContentType string
+}
+
+// MemoryStore guarda os objetos em memória. UploadErr, quando definido, é
+// devolvido por todos os uploads para simular indisponibilidade do storage.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	objects   map[string]MemoryObject
+	UploadErr error
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		objects: make(map[string]MemoryObject),
+	}
+}
+
+func (m *MemoryStore) Put(objectName string, data []byte, contentType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[objectName] = MemoryObject{Data: data, ContentType: contentType}
+}
+
+func (m *MemoryStore) Get(objectName string) (MemoryObject, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	object, ok := m.objects[objectName]
+	return object, ok
+}
+
+func (m *MemoryStore) ObjectNames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.objects))
+	for name := range m.objects {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (m *MemoryStore) UploadFileWithContentType(ctx context.Context, objectName string, file io.Reader, size int64, contentType string) (string, error) {
+	if m.UploadErr != nil {
+		return "", m.UploadErr
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+
+	m.Put(objectName, data, contentType)
+	return fmt.Sprintf("memory://%s", objectName), nil
+}
+
+func (m *MemoryStore) UploadString(ctx context.Context, objectName string, content string) error {
+	if m.UploadErr != nil {
+		return m.UploadErr
+	}
+
+	m.Put(objectName, []byte(content), "text/plain")
+	return nil
+}
+
+func (m *MemoryStore) DownloadFile(ctx context.Context, objectName, localPath string) error {
+	object, ok := m.Get(objectName)
+	if !ok {
+		return fmt.Errorf("%w: %s", storage.ErrNotFound, objectName)
+	}
+
+	if err := os.WriteFile(localPath, object.Data, 0644); err != nil {
+		return fmt.Errorf("erro ao criar arquivo local: %w", err)
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteFile(ctx context.Context, objectName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, objectName)
+	return nil
+}
+
+func (m *MemoryStore) GetFileURL(ctx context.Context, objectName string, expires time.Duration) (string, error) {
+	if _, ok := m.Get(objectName); !ok {
+		return "", fmt.Errorf("%w: %s", storage.ErrNotFound, objectName)
+	}
+	return fmt.Sprintf("memory://%s?expires=%d", objectName, int(expires.Seconds())), nil
+}
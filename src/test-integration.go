@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"src/internal/api"
 	"src/internal/cache"
 	"src/internal/config"
 	"src/internal/models"
@@ -77,7 +78,7 @@ func main() {
 	defer rabbitMQClient.Close()
 
 	// Testar publisher
-	publisher := queue.NewPublisher(rabbitMQClient.GetChannel())
+	publisher := queue.NewPublisher(rabbitMQClient)
 	fmt.Println("✅ Publisher criado com sucesso")
 
 	// Testar processor
@@ -85,7 +86,7 @@ func main() {
 	fmt.Println("✅ Processor criado com sucesso")
 
 	// Testar consumer (apenas criar, não usar)
-	_ = queue.NewConsumer(rabbitMQClient.GetChannel(), processor, minioClient)
+	_ = queue.NewConsumer(rabbitMQClient, processor, minioClient, redisClient, api.NewHTTPClientFromEnv(), 1)
 	fmt.Println("✅ Consumer criado com sucesso")
 
 	// Testar job de processamento
@@ -107,7 +108,7 @@ func main() {
 	fmt.Println("✅ Job publicado com sucesso")
 
 	// Testar processamento
-	result := processor.ProcessVideo(job)
+	result := processor.ProcessVideo(ctx, job, nil)
 	fmt.Printf("✅ Processamento testado: %s\n", result.Status)
 
 	// Testar cache de status de processamento